@@ -0,0 +1,298 @@
+package core
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates Tickers and Timers
+//
+// Use RealClock in production code and FakeClock in tests
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// NewTicker returns a new Ticker that ticks every given duration
+	NewTicker(every time.Duration) Ticker
+
+	// NewTimer returns a new Timer that fires once after the given duration
+	NewTimer(duration time.Duration) Timer
+
+	// After returns a channel that receives the current time after the given duration
+	After(duration time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks at regular intervals
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+
+	// Reset stops the Ticker and resets its period to the given duration
+	Reset(every time.Duration)
+
+	// Stop turns off the Ticker
+	Stop()
+}
+
+// Timer fires once after a duration
+type Timer interface {
+	// C returns the channel on which the time is delivered when the Timer fires
+	C() <-chan time.Time
+
+	// Reset changes the Timer to fire after the given duration, it returns true if the Timer was active
+	Reset(duration time.Duration) bool
+
+	// Stop prevents the Timer from firing, it returns true if the Timer was active
+	Stop() bool
+}
+
+// RealClock is a Clock that uses the wall clock
+type RealClock struct{}
+
+// Now returns the current local time
+//
+// implements Clock
+func (clock RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns a new Ticker backed by a time.Ticker
+//
+// implements Clock
+func (clock RealClock) NewTicker(every time.Duration) Ticker {
+	return realTicker{time.NewTicker(every)}
+}
+
+// NewTimer returns a new Timer backed by a time.Timer
+//
+// implements Clock
+func (clock RealClock) NewTimer(duration time.Duration) Timer {
+	return realTimer{time.NewTimer(duration)}
+}
+
+// After returns a channel that receives the current time after the given duration
+//
+// implements Clock
+func (clock RealClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (ticker realTicker) C() <-chan time.Time {
+	return ticker.Ticker.C
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (timer realTimer) C() <-chan time.Time {
+	return timer.Timer.C
+}
+
+// FakeClock is a Clock that only moves when told to
+//
+// Tickers and Timers created by a FakeClock fire when the clock is advanced or set past their next tick.
+type FakeClock struct {
+	now     time.Time
+	tickers []*fakeTicker
+	timers  []*fakeTimer
+	mutex   sync.Mutex
+}
+
+// NewFakeClock returns a new FakeClock set at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the FakeClock
+//
+// implements Clock
+func (clock *FakeClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+// NewTicker returns a new Ticker that fires when the FakeClock is advanced
+//
+// implements Clock
+func (clock *FakeClock) NewTicker(every time.Duration) Ticker {
+	if every <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	ticker := &fakeTicker{
+		clock: clock,
+		c:     make(chan time.Time, 1),
+		every: every,
+		next:  clock.now.Add(every),
+	}
+	clock.tickers = append(clock.tickers, ticker)
+	return ticker
+}
+
+// NewTimer returns a new Timer that fires when the FakeClock is advanced past the given duration
+//
+// implements Clock
+func (clock *FakeClock) NewTimer(duration time.Duration) Timer {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	timer := &fakeTimer{
+		clock: clock,
+		c:     make(chan time.Time, 1),
+		at:    clock.now.Add(duration),
+	}
+	clock.timers = append(clock.timers, timer)
+	clock.fire()
+	return timer
+}
+
+// After returns a channel that receives the time when the FakeClock is advanced past the given duration
+//
+// implements Clock
+func (clock *FakeClock) After(duration time.Duration) <-chan time.Time {
+	return clock.NewTimer(duration).C()
+}
+
+// Advance moves the FakeClock forward by the given duration, firing the Tickers and Timers that are due
+//
+// Like a time.Ticker, a fake Ticker drops ticks if its reader is too slow.
+func (clock *FakeClock) Advance(duration time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = clock.now.Add(duration)
+	clock.fire()
+}
+
+// Set sets the FakeClock at the given time, firing the Tickers and Timers that are due
+//
+// The FakeClock can be set back in time, the Tickers and Timers then wait for the clock to catch up.
+func (clock *FakeClock) Set(now time.Time) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = now
+	clock.fire()
+}
+
+// PendingTimers returns the number of Timers of the FakeClock that have not fired yet
+//
+// Tests use it to wait for the code under test to start waiting before they advance the clock.
+func (clock *FakeClock) PendingTimers() int {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return len(clock.timers)
+}
+
+// ActiveTickers returns the number of Tickers of the FakeClock that are not stopped
+//
+// Tests use it to check that the code under test stops its Tickers.
+func (clock *FakeClock) ActiveTickers() int {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return len(clock.tickers)
+}
+
+// fire fires the Tickers and Timers that are due, the clock must be locked
+func (clock *FakeClock) fire() {
+	pending := clock.timers[:0]
+	for _, timer := range clock.timers {
+		if timer.at.After(clock.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		select {
+		case timer.c <- clock.now:
+		default:
+		}
+	}
+	clear(clock.timers[len(pending):])
+	clock.timers = pending
+	for _, ticker := range clock.tickers {
+		for !ticker.next.After(clock.now) {
+			select {
+			case ticker.c <- ticker.next:
+			default:
+			}
+			ticker.next = ticker.next.Add(ticker.every)
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock *FakeClock
+	c     chan time.Time
+	every time.Duration
+	next  time.Time
+}
+
+func (ticker *fakeTicker) C() <-chan time.Time {
+	return ticker.c
+}
+
+func (ticker *fakeTicker) Reset(every time.Duration) {
+	if every <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	ticker.clock.mutex.Lock()
+	defer ticker.clock.mutex.Unlock()
+	ticker.every = every
+	ticker.next = ticker.clock.now.Add(every)
+	ticker.clock.removeTicker(ticker)
+	ticker.clock.tickers = append(ticker.clock.tickers, ticker)
+}
+
+func (ticker *fakeTicker) Stop() {
+	ticker.clock.mutex.Lock()
+	defer ticker.clock.mutex.Unlock()
+	ticker.clock.removeTicker(ticker)
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	c     chan time.Time
+	at    time.Time
+}
+
+func (timer *fakeTimer) C() <-chan time.Time {
+	return timer.c
+}
+
+func (timer *fakeTimer) Reset(duration time.Duration) bool {
+	timer.clock.mutex.Lock()
+	defer timer.clock.mutex.Unlock()
+	active := timer.clock.removeTimer(timer)
+	timer.at = timer.clock.now.Add(duration)
+	timer.clock.timers = append(timer.clock.timers, timer)
+	timer.clock.fire()
+	return active
+}
+
+func (timer *fakeTimer) Stop() bool {
+	timer.clock.mutex.Lock()
+	defer timer.clock.mutex.Unlock()
+	return timer.clock.removeTimer(timer)
+}
+
+// removeTicker removes a Ticker from the active Tickers, the clock must be locked
+func (clock *FakeClock) removeTicker(ticker *fakeTicker) {
+	if index := slices.Index(clock.tickers, ticker); index >= 0 {
+		clock.tickers = slices.Delete(clock.tickers, index, index+1)
+	}
+}
+
+// removeTimer removes a Timer from the pending Timers, the clock must be locked
+func (clock *FakeClock) removeTimer(timer *fakeTimer) bool {
+	for index, pending := range clock.timers {
+		if pending == timer {
+			clock.timers = slices.Delete(clock.timers, index, index+1)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanFireFakeTimers(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := core.NewFakeClock(start)
+	timer := clock.NewTimer(time.Minute)
+	after := clock.After(2 * time.Minute)
+	assert.Equal(t, 2, clock.PendingTimers())
+
+	clock.Advance(59 * time.Second)
+	assertNotFired(t, timer.C())
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-timer.C())
+	assertNotFired(t, after)
+	assert.Equal(t, 1, clock.PendingTimers())
+	assert.False(t, timer.Stop(), "The timer should have fired already")
+
+	assert.False(t, timer.Reset(time.Minute), "The timer was not active")
+	assert.True(t, timer.Stop(), "The timer should have been active")
+	clock.Advance(time.Hour)
+	assertNotFired(t, timer.C())
+	assert.Equal(t, start.Add(61*time.Minute), <-after)
+	assert.Equal(t, 0, clock.PendingTimers())
+
+	immediate := clock.NewTimer(0)
+	assert.Equal(t, start.Add(61*time.Minute), <-immediate.C())
+}
+
+func TestCanSetFakeClock(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := core.NewFakeClock(start)
+	ticker := clock.NewTicker(time.Hour)
+	timer := clock.NewTimer(90 * time.Minute)
+
+	clock.Set(start.Add(-time.Hour))
+	assert.Equal(t, start.Add(-time.Hour), clock.Now())
+	assertNotFired(t, ticker.C())
+	assertNotFired(t, timer.C())
+
+	clock.Set(start.Add(2 * time.Hour))
+	assert.Equal(t, start.Add(time.Hour), <-ticker.C())
+	assert.Equal(t, start.Add(2*time.Hour), <-timer.C())
+	ticker.Stop()
+}
+
+func TestShouldRemoveStoppedFakeTickers(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := core.NewFakeClock(start)
+	ticker := clock.NewTicker(time.Minute)
+	other := clock.NewTicker(time.Hour)
+	assert.Equal(t, 2, clock.ActiveTickers())
+
+	ticker.Stop()
+	ticker.Stop() // stopping twice is fine
+	assert.Equal(t, 1, clock.ActiveTickers())
+	clock.Advance(time.Minute)
+	assertNotFired(t, ticker.C())
+
+	ticker.Reset(time.Minute)
+	assert.Equal(t, 2, clock.ActiveTickers())
+	ticker.Reset(2 * time.Minute)
+	assert.Equal(t, 2, clock.ActiveTickers(), "Resetting an active ticker should not add it twice")
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, start.Add(3*time.Minute), <-ticker.C())
+
+	ticker.Stop()
+	other.Stop()
+	assert.Equal(t, 0, clock.ActiveTickers())
+}
+
+func TestCanUseRealClockTimers(t *testing.T) {
+	clock := core.RealClock{}
+	timer := clock.NewTimer(time.Millisecond)
+	at := <-timer.C()
+	assert.False(t, at.IsZero())
+	assert.False(t, timer.Stop())
+	assert.False(t, (<-clock.After(time.Millisecond)).IsZero())
+}
+
+func assertNotFired(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case at := <-c:
+		assert.Failf(t, "The channel should not have fired", "fired at %s", at)
+	default:
+	}
+}
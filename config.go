@@ -0,0 +1,279 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// EnvSource describes a source of environment variables
+type EnvSource interface {
+	// Environment returns a snapshot of the variables of this source
+	Environment() (map[string]string, error)
+
+	// Origin tells where the variables of this source come from
+	Origin() ConfigOrigin
+}
+
+// ConfigOrigin tells where a configuration value comes from
+type ConfigOrigin string
+
+const (
+	// ConfigOriginEnv is the origin of values read from the process environment
+	ConfigOriginEnv ConfigOrigin = "env"
+	// ConfigOriginFile is the origin of values read from files
+	ConfigOriginFile ConfigOrigin = "file"
+	// ConfigOriginDefault is the origin of values that were not found in any source
+	ConfigOriginDefault ConfigOrigin = "default"
+)
+
+// OSEnvironment is an EnvSource that reads the environment of the current process
+type OSEnvironment struct{}
+
+// EnvFile is an EnvSource that reads a dotenv file
+//
+// Each line of the file is of the form KEY=VALUE, "export KEY=VALUE" is also accepted.
+// Empty lines and lines starting with # are ignored, values can be single or double quoted.
+//
+// A missing file is considered empty.
+type EnvFile string
+
+// SecretsDirectory is an EnvSource that reads a directory of secret files
+//
+// Each regular file in the directory is a variable, its name is the key and its content is the value
+// (e.g.: /run/secrets/DB_PASSWORD). Trailing newlines are removed from the values.
+//
+// A missing directory is considered empty.
+type SecretsDirectory string
+
+// ConfigChange describes a configuration variable that changed
+//
+// The values of secrets (see IsSecretConfigKey and the `secret:"true"` tag) are replaced by RedactedValue.
+type ConfigChange struct {
+	Key      string `json:"key"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// configValue is a raw configuration value and its origin
+type configValue struct {
+	Value  string
+	Origin ConfigOrigin
+	Secret bool
+}
+
+// Environment returns the environment of the current process
+//
+// implements EnvSource
+func (source OSEnvironment) Environment() (map[string]string, error) {
+	environment := map[string]string{}
+	for _, variable := range os.Environ() {
+		if key, value, found := strings.Cut(variable, "="); found {
+			environment[key] = value
+		}
+	}
+	return environment, nil
+}
+
+// Origin returns ConfigOriginEnv
+//
+// implements EnvSource
+func (source OSEnvironment) Origin() ConfigOrigin {
+	return ConfigOriginEnv
+}
+
+// Environment returns the variables of the dotenv file
+//
+// implements EnvSource
+func (source EnvFile) Environment() (map[string]string, error) {
+	environment := map[string]string{}
+	file, err := os.Open(string(source))
+	if errors.Is(err, os.ErrNotExist) {
+		return environment, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: missing '='", source, number)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		environment[strings.TrimSpace(key)] = value
+	}
+	return environment, scanner.Err()
+}
+
+// Origin returns ConfigOriginFile
+//
+// implements EnvSource
+func (source EnvFile) Origin() ConfigOrigin {
+	return ConfigOriginFile
+}
+
+// Environment returns the secrets stored in the directory
+//
+// implements EnvSource
+func (source SecretsDirectory) Environment() (map[string]string, error) {
+	environment := map[string]string{}
+	entries, err := os.ReadDir(string(source))
+	if errors.Is(err, os.ErrNotExist) {
+		return environment, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(string(source), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		environment[entry.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+	return environment, nil
+}
+
+// Origin returns ConfigOriginFile
+//
+// implements EnvSource
+func (source SecretsDirectory) Origin() ConfigOrigin {
+	return ConfigOriginFile
+}
+
+// LoadConfig populates a config struct from the given sources
+//
+// The fields of the struct are bound to variables with the "env" tag, a "default" tag gives the value
+// to use when the variable is not found in any source. Nested structs without an "env" tag are walked.
+//
+// When a variable is present in more than one source, the last source wins.
+// If no source is given, the environment of the current process is used.
+//
+// Example:
+//
+//	type Config struct {
+//		Host     string        `env:"DB_HOST" default:"localhost"`
+//		Port     int           `env:"DB_PORT" default:"5432"`
+//		Timeout  time.Duration `env:"DB_TIMEOUT" default:"PT30S"`
+//		Password string        `env:"DB_PASSWORD"`
+//	}
+//
+//	var config Config
+//	err := core.LoadConfig(&config, core.OSEnvironment{}, core.SecretsDirectory("/run/secrets"))
+func LoadConfig(config any, sources ...EnvSource) error {
+	_, err := loadConfig(config, sources)
+	return err
+}
+
+// loadConfig populates the config and returns the raw values per key
+func loadConfig(config any, sources []EnvSource) (map[string]configValue, error) {
+	target := reflect.ValueOf(config)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config must be a non-nil pointer to a struct")
+	}
+	environment, err := readEnvSources(sources)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]configValue{}
+	err = populateConfig(target.Elem(), environment, values)
+	return values, err
+}
+
+// readEnvSources merges the variables of the given sources, the last source wins
+func readEnvSources(sources []EnvSource) (map[string]configValue, error) {
+	if len(sources) == 0 {
+		sources = []EnvSource{OSEnvironment{}}
+	}
+	environment := map[string]configValue{}
+	for _, source := range sources {
+		variables, err := source.Environment()
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range variables {
+			if len(value) > 0 {
+				environment[key] = configValue{Value: value, Origin: source.Origin()}
+			}
+		}
+	}
+	return environment, nil
+}
+
+func populateConfig(target reflect.Value, environment map[string]configValue, values map[string]configValue) error {
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, tagged := field.Tag.Lookup("env")
+		if !tagged {
+			if field.Type.Kind() == reflect.Struct {
+				if err := populateConfig(target.Field(i), environment, values); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		value, found := environment[key]
+		if !found {
+			if value.Value, found = field.Tag.Lookup("default"); !found {
+				continue
+			}
+			value.Origin = ConfigOriginDefault
+		}
+		if err := parseValue(target.Field(i), value.Value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		value.Secret = field.Tag.Get("secret") == "true" || IsSecretConfigKey(key)
+		values[key] = value
+	}
+	return nil
+}
+
+// diffConfigValues returns the changes between two sets of raw values, sorted by key
+func diffConfigValues(previous, current map[string]configValue) (changes []ConfigChange) {
+	for key, value := range current {
+		if old, found := previous[key]; !found || old.Value != value.Value {
+			changes = append(changes, newConfigChange(key, old, value))
+		}
+	}
+	for key, value := range previous {
+		if _, found := current[key]; !found {
+			changes = append(changes, newConfigChange(key, value, configValue{}))
+		}
+	}
+	Sort(changes, func(a, b ConfigChange) bool { return a.Key < b.Key })
+	return changes
+}
+
+// newConfigChange creates a ConfigChange, the values of secrets are redacted
+func newConfigChange(key string, previous, current configValue) ConfigChange {
+	change := ConfigChange{Key: key, Previous: previous.Value, Current: current.Value}
+	if previous.Secret || current.Secret {
+		change.Redacted = true
+		if len(change.Previous) > 0 {
+			change.Previous = RedactedValue
+		}
+		if len(change.Current) > 0 {
+			change.Current = RedactedValue
+		}
+	}
+	return change
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+)
+
+// ConfigDumpEntry describes a configuration value in a ConfigDump
+type ConfigDumpEntry struct {
+	Key      string       `json:"key"`
+	Field    string       `json:"field,omitempty"`
+	Value    string       `json:"value"`
+	Origin   ConfigOrigin `json:"origin,omitempty"`
+	Redacted bool         `json:"redacted,omitempty"`
+}
+
+// ConfigDump is a list of configuration values, with their secrets redacted
+//
+// It marshals to JSON as an array and prints as a table. It can be sent as is with RespondWithJSON.
+type ConfigDump []ConfigDumpEntry
+
+// RedactedValue is the value shown in a ConfigDump instead of a secret
+const RedactedValue = "********"
+
+// SecretConfigPatterns contains the patterns of the keys that are redacted in a ConfigDump
+//
+// The patterns are matched with path.Match against the upper-cased keys.
+// Fields tagged with `secret:"true"` are always redacted.
+var SecretConfigPatterns = []string{"*_PASSWORD", "*_TOKEN", "*_SECRET", "*_API_KEY"}
+
+// EnvRecorder records the environment variables read with the GetEnvAs functions
+//
+// Recording is off until an EnvRecorder is given to RecordEnv.
+type EnvRecorder struct {
+	values map[string]ConfigDumpEntry
+	mutex  sync.Mutex
+}
+
+var envRecorder atomic.Pointer[EnvRecorder]
+
+// DumpConfig returns the values of a config struct loaded with LoadConfig
+//
+// The sources are read again to find the origin of each value,
+// they should be the same as the ones given to LoadConfig.
+func DumpConfig(config any, sources ...EnvSource) (ConfigDump, error) {
+	target := reflect.ValueOf(config)
+	if target.Kind() == reflect.Pointer && !target.IsNil() {
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return nil, errors.New("config must be a struct or a pointer to a struct")
+	}
+	environment, err := readEnvSources(sources)
+	if err != nil {
+		return nil, err
+	}
+	return dumpConfig(target, environment), nil
+}
+
+// NewEnvRecorder creates a new EnvRecorder
+func NewEnvRecorder() *EnvRecorder {
+	return &EnvRecorder{values: map[string]ConfigDumpEntry{}}
+}
+
+// RecordEnv records the reads of the GetEnvAs functions in the given EnvRecorder, until RecordEnv is called again
+//
+// If recorder is nil, the reads are not recorded anymore.
+//
+// Example:
+//
+//	recorder := core.NewEnvRecorder()
+//	core.RecordEnv(recorder)
+//	defer core.RecordEnv(nil)
+//	port := core.GetEnvAsInt("PORT", 80)
+//	log.Printf("Environment:\n%s", recorder.Dump())
+func RecordEnv(recorder *EnvRecorder) {
+	envRecorder.Store(recorder)
+}
+
+// Dump returns the environment variables that were recorded
+//
+// The origin of each value is either ConfigOriginEnv or ConfigOriginDefault (when the fallback was used).
+func (recorder *EnvRecorder) Dump() ConfigDump {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	dump := make(ConfigDump, 0, len(recorder.values))
+	for _, entry := range recorder.values {
+		dump = append(dump, entry)
+	}
+	Sort(dump, func(a, b ConfigDumpEntry) bool { return a.Key < b.Key })
+	return dump
+}
+
+// Dump returns the values of the current config
+func (watcher *ConfigWatcher[T]) Dump() ConfigDump {
+	watcher.mutex.Lock()
+	values := watcher.values
+	watcher.mutex.Unlock()
+	return dumpConfig(reflect.ValueOf(watcher.Config()).Elem(), values)
+}
+
+// ServeHTTP sends the values of the current config as JSON
+//
+// implements http.Handler
+func (watcher *ConfigWatcher[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	RespondWithJSON(w, http.StatusOK, watcher.Dump())
+}
+
+// WriteTable writes the ConfigDump as a table
+func (dump ConfigDump) WriteTable(w io.Writer) error {
+	writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "KEY\tVALUE\tORIGIN")
+	for _, entry := range dump {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\n", entry.Key, entry.Value, entry.Origin)
+	}
+	return writer.Flush()
+}
+
+// String returns the ConfigDump as a table
+//
+// implements fmt.Stringer
+func (dump ConfigDump) String() string {
+	var builder strings.Builder
+	_ = dump.WriteTable(&builder)
+	return builder.String()
+}
+
+// IsSecretConfigKey tells if the given key matches one of the SecretConfigPatterns
+func IsSecretConfigKey(key string) bool {
+	for _, pattern := range SecretConfigPatterns {
+		if matched, _ := path.Match(pattern, strings.ToUpper(key)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpConfig walks the config struct like populateConfig does
+func dumpConfig(target reflect.Value, values map[string]configValue) (dump ConfigDump) {
+	var walk func(target reflect.Value, prefix string)
+	walk = func(target reflect.Value, prefix string) {
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key, tagged := field.Tag.Lookup("env")
+			if !tagged {
+				if field.Type.Kind() == reflect.Struct {
+					walk(target.Field(i), prefix+field.Name+".")
+				}
+				continue
+			}
+			entry := ConfigDumpEntry{
+				Key:    key,
+				Field:  prefix + field.Name,
+				Value:  formatConfigValue(target.Field(i)),
+				Origin: values[key].Origin,
+			}
+			if len(entry.Origin) == 0 {
+				if _, found := field.Tag.Lookup("default"); found {
+					entry.Origin = ConfigOriginDefault
+				}
+			}
+			if field.Tag.Get("secret") == "true" || IsSecretConfigKey(key) {
+				entry = entry.redact()
+			}
+			dump = append(dump, entry)
+		}
+	}
+	walk(target, "")
+	return dump
+}
+
+// recordEnvRead records a read from the GetEnvAs functions in the EnvRecorder given to RecordEnv, if any
+func recordEnvRead(name string, value any, found bool) {
+	recorder := envRecorder.Load()
+	if recorder == nil {
+		return
+	}
+	entry := ConfigDumpEntry{Key: name, Value: formatConfigValue(reflect.ValueOf(value)), Origin: ConfigOriginEnv}
+	if !found {
+		entry.Origin = ConfigOriginDefault
+	}
+	if IsSecretConfigKey(name) {
+		entry = entry.redact()
+	}
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if recorder.values == nil {
+		recorder.values = map[string]ConfigDumpEntry{}
+	}
+	recorder.values[name] = entry
+}
+
+func (entry ConfigDumpEntry) redact() ConfigDumpEntry {
+	if len(entry.Value) > 0 {
+		entry.Value = RedactedValue
+	}
+	entry.Redacted = true
+	return entry
+}
+
+func formatConfigValue(value reflect.Value) string {
+	if !value.IsValid() || (value.Kind() == reflect.Pointer && value.IsNil()) {
+		return ""
+	}
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	if value.CanAddr() {
+		if stringer, ok := value.Addr().Interface().(fmt.Stringer); ok {
+			return stringer.String()
+		}
+	}
+	if value.Kind() == reflect.Pointer {
+		return formatConfigValue(value.Elem())
+	}
+	if value.Kind() == reflect.Slice {
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = formatConfigValue(value.Index(i))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,99 @@
+package core_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type APIConfig struct {
+	Database DatabaseConfig
+	User     string `env:"API_USER" default:"admin"`
+	Key      string `env:"API_SIGNING" secret:"true"`
+	Token    string `env:"API_TOKEN"`
+}
+
+func TestCanDumpConfig(t *testing.T) {
+	t.Setenv("DB_HOST", "db.acme.com")
+	t.Setenv("API_SIGNING", "s1gn1ng")
+	secrets := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secrets, "DB_PASSWORD"), []byte("s3cr3t\n"), 0600))
+
+	var config APIConfig
+	sources := []core.EnvSource{core.OSEnvironment{}, core.SecretsDirectory(secrets)}
+	require.NoError(t, core.LoadConfig(&config, sources...))
+
+	dump, err := core.DumpConfig(&config, sources...)
+	require.NoError(t, err, "Failed to dump config")
+	expected := core.ConfigDump{
+		{Key: "DB_HOST", Field: "Database.Host", Value: "db.acme.com", Origin: core.ConfigOriginEnv},
+		{Key: "DB_PORT", Field: "Database.Port", Value: "5432", Origin: core.ConfigOriginDefault},
+		{Key: "DB_TIMEOUT", Field: "Database.Timeout", Value: "30s", Origin: core.ConfigOriginDefault},
+		{Key: "DB_PASSWORD", Field: "Database.Password", Value: core.RedactedValue, Origin: core.ConfigOriginFile, Redacted: true},
+		{Key: "API_USER", Field: "User", Value: "admin", Origin: core.ConfigOriginDefault},
+		{Key: "API_SIGNING", Field: "Key", Value: core.RedactedValue, Origin: core.ConfigOriginEnv, Redacted: true},
+		{Key: "API_TOKEN", Field: "Token", Value: "", Redacted: true},
+	}
+	assert.Equal(t, expected, dump)
+
+	table := dump.String()
+	assert.Contains(t, table, "KEY")
+	assert.Contains(t, table, "db.acme.com")
+	assert.NotContains(t, table, "s3cr3t")
+	assert.NotContains(t, table, "s1gn1ng")
+}
+
+func TestCanDumpEnv(t *testing.T) {
+	t.Setenv("TEST_DUMP_HOST", "www.acme.com")
+	t.Setenv("TEST_DUMP_PASSWORD", "s3cr3t")
+
+	_ = core.GetEnvAsString("TEST_DUMP_HOST", "localhost") // not recorded yet
+
+	recorder := core.NewEnvRecorder()
+	core.RecordEnv(recorder)
+	_ = core.GetEnvAsString("TEST_DUMP_HOST", "localhost")
+	_ = core.GetEnvAsString("TEST_DUMP_PASSWORD", "")
+	_ = core.GetEnvAsInt("TEST_DUMP_PORT", 80)
+	core.RecordEnv(nil)
+	_ = core.GetEnvAsInt("TEST_DUMP_TIMEOUT", 30) // not recorded anymore
+
+	dump := recorder.Dump()
+	expected := core.ConfigDump{
+		{Key: "TEST_DUMP_HOST", Value: "www.acme.com", Origin: core.ConfigOriginEnv},
+		{Key: "TEST_DUMP_PASSWORD", Value: core.RedactedValue, Origin: core.ConfigOriginEnv, Redacted: true},
+		{Key: "TEST_DUMP_PORT", Value: "80", Origin: core.ConfigOriginDefault},
+	}
+	assert.Equal(t, expected, dump)
+}
+
+func TestCanServeConfigDump(t *testing.T) {
+	envfile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_HOST=db.acme.com\nDB_PASSWORD=s3cr3t\n"), 0600))
+	watcher, err := core.NewConfigWatcher[DatabaseConfig](core.EnvFile(envfile))
+	require.NoError(t, err, "Failed to create watcher")
+
+	server := httptest.NewServer(watcher)
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.NoErrorf(t, err, "Failed to get %s", server.URL)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(res.Body)
+	require.NoErrorf(t, err, "Failed to read body of %s", server.URL)
+	assert.NotContains(t, string(body), "s3cr3t")
+
+	var dump core.ConfigDump
+	require.NoError(t, json.Unmarshal(body, &dump), "Failed to unmarshal JSON")
+	require.Len(t, dump, 4)
+	assert.Equal(t, core.ConfigDumpEntry{Key: "DB_HOST", Field: "Host", Value: "db.acme.com", Origin: core.ConfigOriginFile}, dump[0])
+	assert.Equal(t, core.ConfigDumpEntry{Key: "DB_PASSWORD", Field: "Password", Value: core.RedactedValue, Origin: core.ConfigOriginFile, Redacted: true}, dump[3])
+}
@@ -0,0 +1,93 @@
+package core_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DatabaseConfig struct {
+	Host     string        `env:"DB_HOST" default:"localhost"`
+	Port     int           `env:"DB_PORT" default:"5432"`
+	Timeout  time.Duration `env:"DB_TIMEOUT" default:"PT30S"`
+	Password string        `env:"DB_PASSWORD"`
+}
+
+type ServiceConfig struct {
+	Database  DatabaseConfig
+	Debug     bool          `env:"DEBUG" default:"no"`
+	Endpoint  *url.URL      `env:"ENDPOINT" default:"https://www.acme.com/api"`
+	ID        uuid.UUID     `env:"SERVICE_ID"`
+	Tags      []string      `env:"TAGS"`
+	Retry     core.Duration `env:"RETRY" default:"5s"`
+	unchanged string
+}
+
+func TestCanLoadConfigFromEnvironment(t *testing.T) {
+	t.Setenv("DB_HOST", "db.acme.com")
+	t.Setenv("DEBUG", "on")
+	t.Setenv("SERVICE_ID", "b934e02c-96cb-4de3-ba7c-3b7daf593131")
+	t.Setenv("TAGS", "one, two,three")
+
+	var config ServiceConfig
+	err := core.LoadConfig(&config)
+	require.NoError(t, err, "Failed to load config")
+	assert.Equal(t, "db.acme.com", config.Database.Host)
+	assert.Equal(t, 5432, config.Database.Port)
+	assert.Equal(t, 30*time.Second, config.Database.Timeout)
+	assert.Empty(t, config.Database.Password)
+	assert.True(t, config.Debug)
+	require.NotNil(t, config.Endpoint)
+	assert.Equal(t, "https://www.acme.com/api", config.Endpoint.String())
+	assert.Equal(t, uuid.MustParse("b934e02c-96cb-4de3-ba7c-3b7daf593131"), config.ID)
+	assert.Equal(t, []string{"one", "two", "three"}, config.Tags)
+	assert.Equal(t, core.Duration(5*time.Second), config.Retry)
+	assert.Empty(t, config.unchanged)
+}
+
+func TestCanLoadConfigFromFiles(t *testing.T) {
+	folder := t.TempDir()
+	envfile := filepath.Join(folder, ".env")
+	err := os.WriteFile(envfile, []byte("# database\nDB_HOST=db.acme.com\nexport DB_PORT=6543\n\nDB_PASSWORD='not the secret'\n"), 0600)
+	require.NoError(t, err, "Failed to write env file")
+	secrets := filepath.Join(folder, "secrets")
+	require.NoError(t, os.Mkdir(secrets, 0700))
+	err = os.WriteFile(filepath.Join(secrets, "DB_PASSWORD"), []byte("s3cr3t\n"), 0600)
+	require.NoError(t, err, "Failed to write secret file")
+
+	var config DatabaseConfig
+	err = core.LoadConfig(&config, core.EnvFile(envfile), core.SecretsDirectory(secrets))
+	require.NoError(t, err, "Failed to load config")
+	assert.Equal(t, "db.acme.com", config.Host)
+	assert.Equal(t, 6543, config.Port)
+	assert.Equal(t, "s3cr3t", config.Password)
+
+	err = core.LoadConfig(&config, core.EnvFile(filepath.Join(folder, "missing.env")), core.SecretsDirectory(filepath.Join(folder, "missing")))
+	require.NoError(t, err, "Missing files should be considered empty")
+	assert.Equal(t, "localhost", config.Host)
+}
+
+func TestShouldFailLoadingConfigWithInvalidValues(t *testing.T) {
+	folder := t.TempDir()
+	envfile := filepath.Join(folder, ".env")
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_PORT=hello\n"), 0600))
+
+	var config DatabaseConfig
+	err := core.LoadConfig(&config, core.EnvFile(envfile))
+	require.Error(t, err, "Should have failed to load config")
+	assert.Contains(t, err.Error(), "DB_PORT")
+
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_PORT\n"), 0600))
+	err = core.LoadConfig(&config, core.EnvFile(envfile))
+	require.Error(t, err, "Should have failed to read the env file")
+
+	err = core.LoadConfig(config)
+	require.Error(t, err, "Should have failed to load config in a non pointer")
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ConfigWatcher keeps a config struct up to date with its sources
+//
+// The config is reloaded on demand, regularly, or when the process receives SIGHUP.
+// Each reload populates a new struct that atomically replaces the current one,
+// so readers never see a partially loaded config.
+type ConfigWatcher[T any] struct {
+	sources       []EnvSource
+	clock         Clock
+	config        atomic.Pointer[T]
+	values        map[string]configValue
+	handlers      []func(config *T, changes []ConfigChange)
+	errorHandlers []func(err error)
+	mutex         sync.Mutex
+}
+
+// NewConfigWatcher loads a config from the given sources and returns a ConfigWatcher for it
+//
+// If no source is given, the environment of the current process is used.
+// See LoadConfig for the struct tags.
+func NewConfigWatcher[T any](sources ...EnvSource) (*ConfigWatcher[T], error) {
+	return NewConfigWatcherWithClock[T](RealClock{}, sources...)
+}
+
+// NewConfigWatcherWithClock loads a config from the given sources and returns a ConfigWatcher for it that uses the given Clock
+func NewConfigWatcherWithClock[T any](clock Clock, sources ...EnvSource) (*ConfigWatcher[T], error) {
+	watcher := &ConfigWatcher[T]{sources: sources, clock: clock}
+	config := new(T)
+	values, err := loadConfig(config, sources)
+	if err != nil {
+		return nil, err
+	}
+	watcher.values = values
+	watcher.config.Store(config)
+	return watcher, nil
+}
+
+// Config returns the current config
+//
+// The returned struct must not be modified, it is shared with all readers.
+func (watcher *ConfigWatcher[T]) Config() *T {
+	return watcher.config.Load()
+}
+
+// OnChange registers a handler that is called after each reload that changed the config
+func (watcher *ConfigWatcher[T]) OnChange(handler func(config *T, changes []ConfigChange)) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+	watcher.handlers = append(watcher.handlers, handler)
+}
+
+// OnError registers a handler that is called when a reload triggered by Watch fails
+func (watcher *ConfigWatcher[T]) OnError(handler func(err error)) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+	watcher.errorHandlers = append(watcher.errorHandlers, handler)
+}
+
+// Reload reads the sources again and replaces the current config
+//
+// The OnChange handlers are called if the config changed. If the sources cannot be read
+// or the config cannot be populated, the current config is kept and the error is returned.
+func (watcher *ConfigWatcher[T]) Reload() ([]ConfigChange, error) {
+	watcher.mutex.Lock()
+	config := new(T)
+	values, err := loadConfig(config, watcher.sources)
+	if err != nil {
+		watcher.mutex.Unlock()
+		return nil, err
+	}
+	changes := diffConfigValues(watcher.values, values)
+	if len(changes) == 0 {
+		watcher.mutex.Unlock()
+		return nil, nil
+	}
+	watcher.values = values
+	watcher.config.Store(config)
+	handlers := append([]func(*T, []ConfigChange){}, watcher.handlers...)
+	watcher.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(config, changes)
+	}
+	return changes, nil
+}
+
+// Watch reloads the config every given duration and when the process receives SIGHUP, until the context is done
+//
+// If every is not positive, the config is reloaded only on SIGHUP.
+//
+// Watch returns immediately, errors are sent to the OnError handlers.
+func (watcher *ConfigWatcher[T]) Watch(ctx context.Context, every time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	var stopme, pingme chan bool
+	if every > 0 {
+		started := false
+		stopme, pingme, _ = ExecEveryWithClock(watcher.clock, func(tick int64, at time.Time, changeme chan time.Duration) {
+			if !started { // the config was loaded when the watcher was created
+				started = true
+				return
+			}
+			watcher.reload()
+		}, every)
+	}
+
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-ctx.Done():
+				if stopme != nil {
+					close(stopme)
+				}
+				return
+			case <-hangup:
+				if pingme != nil {
+					pingme <- true
+				} else {
+					watcher.reload()
+				}
+			}
+		}
+	}()
+}
+
+// reload reloads the config and sends errors to the OnError handlers
+func (watcher *ConfigWatcher[T]) reload() {
+	if _, err := watcher.Reload(); err != nil {
+		watcher.mutex.Lock()
+		handlers := append([]func(error){}, watcher.errorHandlers...)
+		watcher.mutex.Unlock()
+		for _, handler := range handlers {
+			handler(err)
+		}
+	}
+}
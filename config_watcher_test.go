@@ -0,0 +1,117 @@
+package core_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanReloadConfig(t *testing.T) {
+	envfile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_HOST=db1.acme.com\nDB_PASSWORD=s3cr3t\n"), 0600))
+
+	watcher, err := core.NewConfigWatcher[DatabaseConfig](core.EnvFile(envfile))
+	require.NoError(t, err, "Failed to create watcher")
+	config := watcher.Config()
+	assert.Equal(t, "db1.acme.com", config.Host)
+
+	var notified []core.ConfigChange
+	watcher.OnChange(func(config *DatabaseConfig, changes []core.ConfigChange) {
+		notified = changes
+	})
+
+	changes, err := watcher.Reload()
+	require.NoError(t, err, "Failed to reload config")
+	assert.Empty(t, changes)
+	assert.Empty(t, notified, "Handlers should not be called without changes")
+
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_HOST=db2.acme.com\nDB_PORT=6543\n"), 0600))
+	changes, err = watcher.Reload()
+	require.NoError(t, err, "Failed to reload config")
+	expected := []core.ConfigChange{
+		{Key: "DB_HOST", Previous: "db1.acme.com", Current: "db2.acme.com"},
+		{Key: "DB_PASSWORD", Previous: core.RedactedValue, Redacted: true},
+		{Key: "DB_PORT", Previous: "5432", Current: "6543"},
+	}
+	assert.Equal(t, expected, changes)
+	assert.Equal(t, expected, notified)
+	assert.Equal(t, "db2.acme.com", watcher.Config().Host)
+	assert.Equal(t, "db1.acme.com", config.Host, "Previous config should not be modified")
+
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_PORT=hello\n"), 0600))
+	_, err = watcher.Reload()
+	require.Error(t, err, "Should have failed to reload config")
+	assert.Equal(t, 6543, watcher.Config().Port, "Config should be kept on error")
+}
+
+func TestCanWatchConfig(t *testing.T) {
+	envfile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_HOST=db1.acme.com\n"), 0600))
+
+	clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	watcher, err := core.NewConfigWatcherWithClock[DatabaseConfig](clock, core.EnvFile(envfile))
+	require.NoError(t, err, "Failed to create watcher")
+
+	notified := make(chan []core.ConfigChange, 1)
+	watcher.OnChange(func(config *DatabaseConfig, changes []core.ConfigChange) {
+		notified <- changes
+	})
+	errors := make(chan error, 1)
+	watcher.OnError(func(err error) {
+		errors <- err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher.Watch(ctx, time.Minute)
+
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_HOST=db2.acme.com\n"), 0600))
+	clock.Advance(30 * time.Second)
+	select {
+	case <-notified:
+		t.Fatal("Config should not be reloaded before the interval")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case changes := <-notified:
+		assert.Equal(t, []core.ConfigChange{{Key: "DB_HOST", Previous: "db1.acme.com", Current: "db2.acme.com"}}, changes)
+	case <-time.After(time.Second):
+		t.Fatal("Config should have been reloaded")
+	}
+	assert.Equal(t, "db2.acme.com", watcher.Config().Host)
+
+	require.NoError(t, os.WriteFile(envfile, []byte("DB_PORT=hello\n"), 0600))
+	clock.Advance(time.Minute)
+	select {
+	case err := <-errors:
+		assert.Contains(t, err.Error(), "DB_PORT")
+	case <-time.After(time.Second):
+		t.Fatal("Reload should have failed")
+	}
+}
+
+func TestShouldRedactSecretsInConfigChanges(t *testing.T) {
+	envfile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envfile, []byte("API_USER=john\nAPI_SIGNING=s1gn1ng\n"), 0600))
+	watcher, err := core.NewConfigWatcher[APIConfig](core.EnvFile(envfile))
+	require.NoError(t, err, "Failed to create watcher")
+
+	require.NoError(t, os.WriteFile(envfile, []byte("API_USER=jane\nAPI_SIGNING=s1gn3d\nAPI_TOKEN=t0k3n\n"), 0600))
+	changes, err := watcher.Reload()
+	require.NoError(t, err, "Failed to reload config")
+	expected := []core.ConfigChange{
+		{Key: "API_SIGNING", Previous: core.RedactedValue, Current: core.RedactedValue, Redacted: true},
+		{Key: "API_TOKEN", Current: core.RedactedValue, Redacted: true},
+		{Key: "API_USER", Previous: "john", Current: "jane"},
+	}
+	assert.Equal(t, expected, changes)
+	assert.Equal(t, "s1gn3d", watcher.Config().Key)
+}
@@ -2,6 +2,7 @@ package core_test
 
 import (
 	"encoding/json"
+	"math/rand/v2"
 	"testing"
 	"time"
 
@@ -130,6 +131,56 @@ func TestCanCalculateExponentialBackoff(t *testing.T) {
 	}
 }
 
+func TestCanExecEveryWithClock(t *testing.T) {
+	clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	ticks := make(chan time.Time, 10)
+	counts := make(chan int64, 10)
+	stopme, pingme, changeme := core.ExecEveryWithClock(clock, func(tick int64, at time.Time, changeme chan time.Duration) {
+		counts <- tick
+		ticks <- at
+	}, time.Minute)
+	defer close(stopme)
+
+	assert.Equal(t, clock.Now(), <-ticks, "The func should run once before the ticker starts")
+	assert.Equal(t, int64(0), <-counts)
+	clock.Advance(time.Minute)
+	assert.Equal(t, clock.Now(), <-ticks)
+	assert.Equal(t, int64(1), <-counts)
+	pingme <- true
+	assert.Equal(t, clock.Now(), <-ticks)
+	assert.Equal(t, int64(1), <-counts, "The runs on demand should not count as ticks")
+	clock.Advance(time.Minute)
+	assert.Equal(t, clock.Now(), <-ticks)
+	assert.Equal(t, int64(2), <-counts)
+	changeme <- time.Hour
+	time.Sleep(10 * time.Millisecond) // let the job reset its ticker
+	clock.Advance(30 * time.Minute)
+	select {
+	case at := <-ticks:
+		assert.Failf(t, "the func should not run before the new interval", "ran at %s", at)
+	case <-time.After(50 * time.Millisecond):
+	}
+	clock.Advance(30 * time.Minute)
+	assert.Equal(t, clock.Now(), <-ticks)
+	assert.Equal(t, int64(3), <-counts)
+}
+
+func TestShouldPanicExecutingEveryNonPositiveInterval(t *testing.T) {
+	assert.Panics(t, func() {
+		core.ExecEvery(func(tick int64, at time.Time, changeme chan time.Duration) {}, 0)
+	})
+}
+
+func TestCanCalculateExponentialBackoffWithRand(t *testing.T) {
+	first := core.ExponentialBackoffWithRand(rand.New(rand.NewPCG(1, 2)), 3, time.Second, 30*time.Second, 0.5)
+	second := core.ExponentialBackoffWithRand(rand.New(rand.NewPCG(1, 2)), 3, time.Second, 30*time.Second, 0.5)
+	assert.Equal(t, first, second, "The same seed should give the same delay")
+	assert.GreaterOrEqual(t, first, 2*time.Second)
+	assert.LessOrEqual(t, first, 6*time.Second)
+
+	assert.Equal(t, 4*time.Second, core.ExponentialBackoffWithRand(rand.New(rand.NewPCG(1, 2)), 3, time.Second, 30*time.Second, 0))
+}
+
 func TestCappedString(t *testing.T) {
 	value := "This is a long string"
 	capped := core.CappedString(value, 10)
@@ -1,7 +1,6 @@
 package core
 
 import (
-	"encoding/json"
 	"net/url"
 	"path"
 	"reflect"
@@ -9,35 +8,39 @@ import (
 )
 
 // DecoratedResource is a resource that contains a Self link
+//
+// It can also carry named Links and Embedded related resources.
+// The Format tells how the resource is marshaled to JSON.
 type DecoratedResource struct {
-	Data    any
-	SelfURI string `json:"selfURI,omitempty"`
+	Data     any
+	SelfURI  string `json:"selfURI,omitempty"`
+	Links    map[string]Link
+	Embedded map[string]any
+	Format   DecorationFormat
+	members  map[string]any
 }
 
 // Decorate decorates a struct that can be identified
+//
+// The fields of the struct with a "core" tag are decorated too:
+//   - `core:"link"` adds a link named after the field to the selfURI of the related resource (not for slices)
+//   - `core:"ref"` replaces the related resource by its reference, with its selfURI (see GetReferenceWithURI)
+//   - `core:"embed"` moves the related resource to the Embedded resources, decorated
+//
+// The options can be combined, like `core:"link,ref"`.
 func Decorate(item any, rootpath string) *DecoratedResource {
-	decorated := &DecoratedResource{Data: item}
-	resourceName := pluralize(strings.ToLower(reflect.TypeOf(item).Name()))
-	switch identifier := item.(type) {
-	case Identifiable:
-		decorated.SelfURI = path.Join(rootpath, resourceName, identifier.GetID().String())
-	case StringIdentifiable:
-		decorated.SelfURI = path.Join(rootpath, resourceName, identifier.GetID())
-	}
-	return decorated
+	return decorate(item, func(_ any, resourceName, id string) string {
+		return path.Join(rootpath, resourceName, id)
+	})
 }
 
 // DecorateWithURL decorates a struct that can be identified with a URL
+//
+// See Decorate for the decoration of the fields of the struct.
 func DecorateWithURL(item any, root url.URL) *DecoratedResource {
-	decorated := &DecoratedResource{Data: item}
-	resourceName := pluralize(strings.ToLower(reflect.TypeOf(item).Name()))
-	switch identifier := item.(type) {
-	case Identifiable:
-		decorated.SelfURI = root.JoinPath(resourceName, identifier.GetID().String()).String()
-	case StringIdentifiable:
-		decorated.SelfURI = root.JoinPath(resourceName, identifier.GetID()).String()
-	}
-	return decorated
+	return decorate(item, func(_ any, resourceName, id string) string {
+		return root.JoinPath(resourceName, id).String()
+	})
 }
 
 // DecorateAll decorates all items in a slice of identifiable items
@@ -51,55 +54,107 @@ func DecorateAll[S ~[]T, T any](items S, rootpath string) []DecoratedResource {
 
 // ResourceName returns the name of a resource
 //
+// If the data is a core.ResourceNamer, it will return its resource name
 // If the data is a core.TypeCarrier, it will return the pluralized name of the type
-// Otherwise, it will return the pluralized name of the type of the data, via reflection
+// Otherwise, it will return the pluralized name of the type of the data, via reflection.
+// Pointers are dereferenced, and the type parameters of generic types are ignored (Box[int] gives "boxes").
+//
+// The words of the name are joined with ResourceNameCaseStyle.
 func (resource DecoratedResource) ResourceName() string {
-	if carrier, ok := resource.Data.(TypeCarrier); ok {
-		return pluralize(strings.ToLower(carrier.GetType()))
-	}
-	dataType := reflect.TypeOf(resource.Data)
-	if dataType.Kind() == reflect.Ptr {
-		return pluralize(strings.ToLower(dataType.Elem().Name()))
-	}
-	return pluralize(strings.ToLower(dataType.Name()))
+	return resourceNameOf(resource.Data)
+}
+
+// AddLink adds a named link to the DecoratedResource
+func (resource *DecoratedResource) AddLink(rel, href string) *DecoratedResource {
+	return resource.addLink(rel, Link{Href: href})
 }
 
-// MarshalJSON marshals the DecoratedResource to JSON
+// AddTemplatedLink adds a named link with an RFC 6570 URI Template to the DecoratedResource
 //
-// implements the json.Marshaler interface
-func (resource DecoratedResource) MarshalJSON() ([]byte, error) {
-	payload, err := json.Marshal(resource.Data)
-	if err != nil {
-		return nil, err
-	}
-	decoration, _ := json.Marshal(struct {
-		SelfURI string `json:"selfURI"`
-	}{
-		SelfURI: resource.SelfURI,
-	})
-	return []byte(strings.TrimRight(string(payload), "}") + ", " + string(decoration)[1:]), nil
+// Example:
+//
+//	decorated.AddTemplatedLink("orders", "/api/v1/users/123/orders{?status,limit}")
+func (resource *DecoratedResource) AddTemplatedLink(rel, template string) *DecoratedResource {
+	return resource.addLink(rel, Link{Href: template, Templated: true})
 }
 
-// pluralize returns the plural form of a given string
+// AddEmbedded embeds a related resource in the DecoratedResource
 //
-// this is a very simple implementation and may not work for all cases
-// It does not handle irregular plurals, such as "child" -> "children"
-func pluralize(name string) string {
-	if strings.HasSuffix(name, "ch") || strings.HasSuffix(name, "sh") {
-		return name + "es"
+// The resource can be a DecoratedResource, a slice of DecoratedResource, or any other value.
+func (resource *DecoratedResource) AddEmbedded(rel string, related any) *DecoratedResource {
+	if resource.Embedded == nil {
+		resource.Embedded = map[string]any{}
 	}
-	if strings.HasSuffix(name, "o") || strings.HasSuffix(name, "s") || strings.HasSuffix(name, "x") {
-		return name + "es"
+	resource.Embedded[rel] = related
+	return resource
+}
+
+// selfURIBuilder builds the selfURI of an item from its resource name and identifier
+type selfURIBuilder func(item any, resourceName, id string) string
+
+// decorate decorates an item and its fields
+func decorate(item any, selfURI selfURIBuilder) *DecoratedResource {
+	return decorateResource(item, selfURI, map[string]bool{})
+}
+
+// decorateResource decorates an item, ancestors contains the selfURIs of the resources that embed it
+func decorateResource(item any, selfURI selfURIBuilder, ancestors map[string]bool) *DecoratedResource {
+	decorated := &DecoratedResource{Data: item}
+	resourceName := resourceNameOf(item)
+	if id, ok := getIdentifier(item); ok {
+		decorated.SelfURI = selfURI(item, resourceName, id)
+		ancestors[decorated.SelfURI] = true
+		defer delete(ancestors, decorated.SelfURI)
 	}
-	if strings.HasSuffix(name, "y") {
-		return name[:len(name)-1] + "ies"
+	decorated.decorateFields(selfURI, ancestors)
+	return decorated
+}
+
+// resourceNameOf returns the resource name of an item, see DecoratedResource.ResourceName
+func resourceNameOf(item any) string {
+	if item == nil {
+		return ""
 	}
-	if strings.HasSuffix(name, "f") {
-		return name[:len(name)-1] + "ves"
+	if value := reflect.ValueOf(item); value.Kind() != reflect.Pointer || !value.IsNil() {
+		if namer, ok := item.(ResourceNamer); ok {
+			return namer.ResourceName()
+		}
+		if carrier, ok := item.(TypeCarrier); ok {
+			return formatResourceName(carrier.GetType())
+		}
 	}
-	if strings.HasSuffix(name, "z") {
-		return name + "zes"
+	return resourceNameOfType(reflect.TypeOf(item))
+}
+
+// resourceNameOfType returns the resource name of a type from its name
+func resourceNameOfType(itemType reflect.Type) string {
+	for itemType.Kind() == reflect.Pointer {
+		itemType = itemType.Elem()
 	}
+	name, _, _ := strings.Cut(itemType.Name(), "[")
+	return formatResourceName(name)
+}
+
+func (resource *DecoratedResource) addLink(rel string, link Link) *DecoratedResource {
+	if resource.Links == nil {
+		resource.Links = map[string]Link{}
+	}
+	resource.Links[rel] = link
+	return resource
+}
 
-	return name + "s"
+// MarshalJSON marshals the DecoratedResource to JSON according to its Format
+//
+// See DecorationFormat for the supported formats.
+//
+// implements the json.Marshaler interface
+func (resource DecoratedResource) MarshalJSON() ([]byte, error) {
+	switch resource.Format {
+	case DecorationFormatHAL:
+		return resource.marshalHAL()
+	case DecorationFormatJSONAPI:
+		return resource.marshalJSONAPI()
+	default:
+		return resource.marshalDefault()
+	}
 }
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+)
+
+// DecoratedCollection is a collection of resources that contains a Self link, its counts, and its paging links
+//
+// Items are marshaled as DecoratedResource (with their SelfURIs and their decorated fields, see Decorate), and unmarshaled back into T,
+// so Go clients can read a decorated collection like:
+//
+//	var users core.DecoratedCollection[User]
+//	err := json.Unmarshal(payload, &users)
+//	next := users.Links["next"].Href
+type DecoratedCollection[T any] struct {
+	Items    []T
+	SelfURIs []string
+	SelfURI  string
+	Count    int
+	Total    int
+	Links    map[string]Link
+	Registry TypeUnmarshaler
+	selfURI  selfURIBuilder
+}
+
+// decoratedCollection is the JSON representation of a DecoratedCollection
+type decoratedCollection struct {
+	SelfURI string            `json:"selfURI,omitempty"`
+	Count   int               `json:"count"`
+	Total   int               `json:"total"`
+	Links   map[string]Link   `json:"links,omitempty"`
+	Items   []json.RawMessage `json:"items"`
+}
+
+// DecorateCollection decorates a slice of identifiable items as a collection
+//
+// The SelfURI of the collection is made of the rootpath and the resource name of the items (see DecoratedResource.ResourceName).
+// The Total is the number of items, use AddPageLinks when the items are a page of a larger collection.
+func DecorateCollection[S ~[]T, T any](items S, rootpath string) *DecoratedCollection[T] {
+	collection := &DecoratedCollection[T]{
+		Items:    make([]T, len(items)),
+		SelfURIs: make([]string, len(items)),
+		Count:    len(items),
+		Total:    len(items),
+		selfURI: func(_ any, resourceName, id string) string {
+			return path.Join(rootpath, resourceName, id)
+		},
+	}
+	copy(collection.Items, items)
+	for i, item := range items {
+		if any(item) != nil {
+			collection.SelfURIs[i] = decorate(item, collection.selfURI).SelfURI
+		}
+	}
+	if resourceName := collectionResourceName(collection.Items); len(resourceName) > 0 {
+		collection.SelfURI = path.Join(rootpath, resourceName)
+	}
+	return collection
+}
+
+// AddLink adds a named link to the DecoratedCollection
+func (collection *DecoratedCollection[T]) AddLink(rel, href string) *DecoratedCollection[T] {
+	if collection.Links == nil {
+		collection.Links = map[string]Link{}
+	}
+	collection.Links[rel] = Link{Href: href}
+	return collection
+}
+
+// AddPageLinks sets the Total of the DecoratedCollection and adds its "first", "prev", "next", and "last" links
+//
+// The links are made of the SelfURI of the collection with the limit and offset query parameters.
+// "prev" is not added on the first page, and "next" is not added on the last page.
+//
+// Example:
+//
+//	core.DecorateCollection(page.Items, "/api/v1").AddPageLinks(request.Offset, request.Limit, page.Total)
+func (collection *DecoratedCollection[T]) AddPageLinks(offset, limit, total int) *DecoratedCollection[T] {
+	collection.Total = total
+	if limit <= 0 {
+		return collection
+	}
+	last := 0
+	if total > 0 {
+		last = ((total - 1) / limit) * limit
+	}
+	collection.AddLink("first", collection.pageURI(0, limit))
+	if offset > 0 {
+		collection.AddLink("prev", collection.pageURI(max(0, min(offset, total)-limit), limit))
+	}
+	if offset+limit < total {
+		collection.AddLink("next", collection.pageURI(offset+limit, limit))
+	}
+	return collection.AddLink("last", collection.pageURI(last, limit))
+}
+
+// MarshalJSON marshals the DecoratedCollection to JSON
+//
+// implements the json.Marshaler interface
+func (collection DecoratedCollection[T]) MarshalJSON() ([]byte, error) {
+	payload := decoratedCollection{
+		SelfURI: collection.SelfURI,
+		Count:   collection.Count,
+		Total:   collection.Total,
+		Links:   collection.Links,
+		Items:   make([]json.RawMessage, len(collection.Items)),
+	}
+	for i, item := range collection.Items {
+		resource := DecoratedResource{Data: item}
+		if collection.selfURI != nil && any(item) != nil {
+			resource = *decorate(item, collection.selfURI)
+		}
+		if i < len(collection.SelfURIs) {
+			resource.SelfURI = collection.SelfURIs[i]
+		}
+		var err error
+		if payload.Items[i], err = resource.MarshalJSON(); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// UnmarshalJSON unmarshals a DecoratedCollection from JSON
+//
+// The items are unmarshaled into T, their SelfURIs are stored in SelfURIs.
+// When T is an interface, the Registry must be set to unmarshal the items (see Decorated).
+//
+// implements the json.Unmarshaler interface
+func (collection *DecoratedCollection[T]) UnmarshalJSON(payload []byte) error {
+	var inner decoratedCollection
+	if err := json.Unmarshal(payload, &inner); err != nil {
+		return err
+	}
+	collection.SelfURI = inner.SelfURI
+	collection.Count = inner.Count
+	collection.Total = inner.Total
+	collection.Links = inner.Links
+	collection.Items = make([]T, len(inner.Items))
+	collection.SelfURIs = make([]string, len(inner.Items))
+	for i, item := range inner.Items {
+		decoration, err := unmarshalDecoratedData(item, &collection.Items[i], collection.Registry)
+		if err != nil {
+			return err
+		}
+		collection.SelfURIs[i] = decoration.SelfURI
+	}
+	return nil
+}
+
+func (collection DecoratedCollection[T]) pageURI(offset, limit int) string {
+	uri, err := url.Parse(collection.SelfURI)
+	if err != nil {
+		uri = &url.URL{Path: collection.SelfURI}
+	}
+	query := uri.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	uri.RawQuery = query.Encode()
+	return uri.String()
+}
+
+// collectionResourceName returns the resource name of the items of a collection
+//
+// When T is an interface, the name comes from the first item.
+func collectionResourceName[T any](items []T) string {
+	itemType := reflect.TypeFor[T]()
+	switch itemType.Kind() {
+	case reflect.Interface:
+		if len(items) == 0 {
+			return ""
+		}
+		return resourceNameOf(items[0])
+	case reflect.Pointer:
+		return resourceNameOf(reflect.New(itemType.Elem()).Interface())
+	default:
+		return resourceNameOf(reflect.Zero(itemType).Interface())
+	}
+}
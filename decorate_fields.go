@@ -0,0 +1,153 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// fieldDecoration tells how the "core" tag of a struct field decorates the related resources it contains
+//
+// The tag options are:
+//   - "link" adds a link named after the field to the selfURI of the related resource (not for slices)
+//   - "ref" replaces the related resource by its reference, with its selfURI (see GetReferenceWithURI)
+//   - "embed" moves the related resource to the Embedded resources, decorated
+//
+// A related resource that embeds the resource being decorated, directly or not, is referenced instead of embedded,
+// so cyclic object graphs are not decorated forever.
+//
+// Example:
+//
+//	type Order struct {
+//		ID       uuid.UUID `json:"id"`
+//		Customer Customer  `json:"customer" core:"link,ref"`
+//		Lines    []Line    `json:"lines"    core:"embed"`
+//	}
+type fieldDecoration struct {
+	link  bool
+	ref   bool
+	embed bool
+}
+
+// decorateFields decorates the fields of the data that have a "core" tag
+//
+// selfURI builds the selfURI of the related resources, ancestors contains the selfURIs of the resources that embed this one.
+func (resource *DecoratedResource) decorateFields(selfURI selfURIBuilder, ancestors map[string]bool) {
+	value := reflect.ValueOf(resource.Data)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, found := field.Tag.Lookup("core")
+		if !found || !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		var decoration fieldDecoration
+		for _, option := range strings.Split(tag, ",") {
+			switch strings.TrimSpace(option) {
+			case "link":
+				decoration.link = true
+			case "ref":
+				decoration.ref = true
+			case "embed":
+				decoration.embed = true
+			}
+		}
+		resource.decorateField(jsonFieldName(field), value.Field(i), decoration, selfURI, ancestors)
+	}
+}
+
+func (resource *DecoratedResource) decorateField(name string, field reflect.Value, decoration fieldDecoration, selfURI selfURIBuilder, ancestors map[string]bool) {
+	for field.Kind() == reflect.Pointer || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return
+		}
+		field = field.Elem()
+	}
+	uriOf := func(related any) (string, bool) {
+		id, ok := getIdentifier(related)
+		if !ok {
+			return "", false
+		}
+		return selfURI(related, resourceNameOf(related), id), true
+	}
+
+	if field.Kind() == reflect.Slice || field.Kind() == reflect.Array {
+		if !decoration.embed && !decoration.ref {
+			return
+		}
+		items := make([]any, 0, field.Len())
+		references := make([]any, 0, field.Len())
+		cyclic := false
+		for i := 0; i < field.Len(); i++ {
+			related := field.Index(i).Interface()
+			uri, ok := uriOf(related)
+			if !ok {
+				return // the items cannot all be identified, the field is left as is
+			}
+			items = append(items, related)
+			references = append(references, reference(related, uri))
+			cyclic = cyclic || ancestors[uri]
+		}
+		if decoration.embed && !cyclic {
+			decorated := make([]DecoratedResource, 0, len(items))
+			for _, related := range items {
+				decorated = append(decorated, *decorateResource(related, selfURI, ancestors))
+			}
+			resource.AddEmbedded(name, decorated)
+			resource.setMember(name, nil)
+			return
+		}
+		resource.setMember(name, references)
+		return
+	}
+
+	related := field.Interface()
+	uri, ok := uriOf(related)
+	if !ok {
+		return
+	}
+	if decoration.link {
+		resource.AddLink(name, uri)
+	}
+	switch {
+	case decoration.embed && !ancestors[uri]:
+		resource.AddEmbedded(name, decorateResource(related, selfURI, ancestors))
+		resource.setMember(name, nil)
+	case decoration.embed || decoration.ref:
+		resource.setMember(name, reference(related, uri))
+	}
+}
+
+// setMember replaces a member of the data when the resource is marshaled, a nil value removes the member
+func (resource *DecoratedResource) setMember(name string, value any) {
+	if resource.members == nil {
+		resource.members = map[string]any{}
+	}
+	resource.members[name] = value
+}
+
+// dataMembers marshals the data of the resource and returns its members, with the members set by decorateFields
+func (resource DecoratedResource) dataMembers() (map[string]json.RawMessage, error) {
+	members, err := dataMembers(resource.Data)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range resource.members {
+		if value == nil {
+			delete(members, name)
+			continue
+		}
+		if members[name], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DecorationFormat tells how a DecoratedResource is marshaled to JSON
+type DecorationFormat string
+
+const (
+	// DecorationFormatDefault adds "selfURI", "links" and "embedded" members next to the members of the data
+	DecorationFormatDefault DecorationFormat = ""
+	// DecorationFormatHAL marshals the resource as HAL, with "_links" and "_embedded" members
+	DecorationFormatHAL DecorationFormat = "hal"
+	// DecorationFormatJSONAPI marshals the resource as a JSON:API document, with "data" and "included" members
+	DecorationFormatJSONAPI DecorationFormat = "jsonapi"
+)
+
+const (
+	// HALContentType is the media type of resources marshaled with DecorationFormatHAL
+	HALContentType = "application/hal+json"
+	// JSONAPIContentType is the media type of resources marshaled with DecorationFormatJSONAPI
+	JSONAPIContentType = "application/vnd.api+json"
+)
+
+// marshalDefault marshals the resource with DecorationFormatDefault
+//
+// Data that does not marshal to a JSON object is given in a "data" member.
+func (resource DecoratedResource) marshalDefault() ([]byte, error) {
+	members, err := resource.dataMembers()
+	if err != nil {
+		return nil, err
+	}
+	if members["selfURI"], err = json.Marshal(resource.SelfURI); err != nil {
+		return nil, err
+	}
+	if len(resource.Links) > 0 {
+		if members["links"], err = json.Marshal(resource.Links); err != nil {
+			return nil, err
+		}
+	}
+	if len(resource.Embedded) > 0 {
+		if members["embedded"], err = marshalEmbedded(resource.Embedded, DecorationFormatDefault); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(members)
+}
+
+// marshalHAL marshals the resource with DecorationFormatHAL
+func (resource DecoratedResource) marshalHAL() ([]byte, error) {
+	members, err := resource.dataMembers()
+	if err != nil {
+		return nil, err
+	}
+	links := make(map[string]Link, len(resource.Links)+1)
+	for rel, link := range resource.Links {
+		links[rel] = link
+	}
+	if len(resource.SelfURI) > 0 {
+		links["self"] = Link{Href: resource.SelfURI}
+	}
+	if len(links) > 0 {
+		if members["_links"], err = json.Marshal(links); err != nil {
+			return nil, err
+		}
+	}
+	if len(resource.Embedded) > 0 {
+		if members["_embedded"], err = marshalEmbedded(resource.Embedded, DecorationFormatHAL); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(members)
+}
+
+// marshalJSONAPI marshals the resource with DecorationFormatJSONAPI
+func (resource DecoratedResource) marshalJSONAPI() ([]byte, error) {
+	included := &jsonAPIIncluded{seen: map[string]bool{}}
+	object, err := resource.jsonAPIObject(included)
+	if err != nil {
+		return nil, err
+	}
+	document := map[string]any{"data": object}
+	if len(included.objects) > 0 {
+		document["included"] = included.objects
+	}
+	return json.Marshal(document)
+}
+
+// jsonAPIIncluded collects the included resources of a JSON:API document
+type jsonAPIIncluded struct {
+	objects []map[string]any
+	seen    map[string]bool
+}
+
+// jsonAPIObject builds the JSON:API resource object of the resource, its embedded resources are added to included
+func (resource DecoratedResource) jsonAPIObject(included *jsonAPIIncluded) (map[string]any, error) {
+	members, err := resource.dataMembers()
+	if err != nil {
+		return nil, err
+	}
+	object := map[string]any{"type": resource.ResourceName()}
+	if id, ok := getIdentifier(resource.Data); ok {
+		object["id"] = id
+	}
+	for key := range members {
+		if strings.EqualFold(key, "id") {
+			delete(members, key)
+		}
+	}
+
+	links := map[string]any{}
+	if len(resource.SelfURI) > 0 {
+		links["self"] = resource.SelfURI
+	}
+	for rel, link := range resource.Links {
+		if link.Templated {
+			links[rel] = map[string]any{"href": link.Href, "meta": map[string]any{"templated": true}}
+		} else {
+			links[rel] = link.Href
+		}
+	}
+	if len(links) > 0 {
+		object["links"] = links
+	}
+
+	relationships := map[string]any{}
+	for _, rel := range slices.Sorted(maps.Keys(resource.Embedded)) { // included resources are in a stable order
+		related := resource.Embedded[rel]
+		relationship, ok, err := jsonAPIRelationship(related, included)
+		if err != nil {
+			return nil, err
+		}
+		if !ok { // related resources without an identifier are attributes
+			if members[rel], err = json.Marshal(related); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		relationships[rel] = relationship
+	}
+	if len(relationships) > 0 {
+		object["relationships"] = relationships
+	}
+	if len(members) > 0 {
+		object["attributes"] = members
+	}
+	return object, nil
+}
+
+// jsonAPIRelationship builds the relationship object of related resources and adds them to included
+//
+// ok is false if one of the related resources cannot be identified.
+func jsonAPIRelationship(related any, included *jsonAPIIncluded) (relationship map[string]any, ok bool, err error) {
+	if related == nil {
+		return map[string]any{"data": nil}, true, nil
+	}
+	items := reflect.ValueOf(related)
+	if (items.Kind() == reflect.Slice || items.Kind() == reflect.Array) && items.Type().Elem().Kind() != reflect.Uint8 {
+		identifiers := []map[string]any{}
+		for i := 0; i < items.Len(); i++ {
+			identifier, ok, err := jsonAPIIdentifier(items.Index(i).Interface(), included)
+			if err != nil || !ok {
+				return nil, ok, err
+			}
+			identifiers = append(identifiers, identifier)
+		}
+		return map[string]any{"data": identifiers}, true, nil
+	}
+	identifier, ok, err := jsonAPIIdentifier(related, included)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	relationship = map[string]any{"data": identifier}
+	if decorated := asDecoratedResource(related); len(decorated.SelfURI) > 0 {
+		relationship["links"] = map[string]any{"related": decorated.SelfURI}
+	}
+	return relationship, true, nil
+}
+
+// jsonAPIIdentifier builds the resource identifier of a related resource and adds the resource to included
+func jsonAPIIdentifier(related any, included *jsonAPIIncluded) (map[string]any, bool, error) {
+	decorated := asDecoratedResource(related)
+	id, ok := getIdentifier(decorated.Data)
+	if !ok {
+		return nil, false, nil
+	}
+	identifier := map[string]any{"type": decorated.ResourceName(), "id": id}
+	key := decorated.ResourceName() + "/" + id
+	if !included.seen[key] {
+		included.seen[key] = true
+		object, err := decorated.jsonAPIObject(included)
+		if err != nil {
+			return nil, false, err
+		}
+		included.objects = append(included.objects, object)
+	}
+	return identifier, true, nil
+}
+
+// asDecoratedResource returns the DecoratedResource of a value, decorating it without links if needed
+func asDecoratedResource(value any) DecoratedResource {
+	switch resource := value.(type) {
+	case DecoratedResource:
+		return resource
+	case *DecoratedResource:
+		return *resource
+	}
+	return DecoratedResource{Data: value}
+}
+
+// marshalEmbedded marshals embedded resources, the DecoratedResource they contain are marshaled with the given format
+func marshalEmbedded(value any, format DecorationFormat) (json.RawMessage, error) {
+	switch resource := value.(type) {
+	case DecoratedResource:
+		resource.Format = format
+		return json.Marshal(resource)
+	case *DecoratedResource:
+		if resource != nil {
+			return marshalEmbedded(*resource, format)
+		}
+	case map[string]any:
+		members := make(map[string]json.RawMessage, len(resource))
+		for key, item := range resource {
+			payload, err := marshalEmbedded(item, format)
+			if err != nil {
+				return nil, err
+			}
+			members[key] = payload
+		}
+		return json.Marshal(members)
+	}
+	items := reflect.ValueOf(value)
+	if (items.Kind() == reflect.Slice || items.Kind() == reflect.Array) && items.Type().Elem().Kind() != reflect.Uint8 && !(items.Kind() == reflect.Slice && items.IsNil()) {
+		payloads := make([]json.RawMessage, items.Len())
+		for i := range payloads {
+			payload, err := marshalEmbedded(items.Index(i).Interface(), format)
+			if err != nil {
+				return nil, err
+			}
+			payloads[i] = payload
+		}
+		return json.Marshal(payloads)
+	}
+	return json.Marshal(value)
+}
+
+// dataMembers marshals data and returns its members
+//
+// If data does not marshal to a JSON object, it is returned as the "data" member.
+func dataMembers(data any) (map[string]json.RawMessage, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	members := map[string]json.RawMessage{}
+	if bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) {
+		if err := json.Unmarshal(payload, &members); err != nil {
+			return nil, err
+		}
+		return members, nil
+	}
+	members["data"] = payload
+	return members, nil
+}
@@ -17,3 +17,56 @@ func TestPluralize(t *testing.T) {
 	assert.Equal(t, "fishes", pluralize("fish"))
 	assert.Equal(t, "leaves", pluralize("leaf"))
 }
+
+func TestPluralizeIrregularWords(t *testing.T) {
+	assert.Equal(t, "days", pluralize("day"))
+	assert.Equal(t, "keys", pluralize("key"))
+	assert.Equal(t, "children", pluralize("child"))
+	assert.Equal(t, "people", pluralize("person"))
+	assert.Equal(t, "People", pluralize("Person"))
+	assert.Equal(t, "roofs", pluralize("roof"))
+	assert.Equal(t, "chiefs", pluralize("chief"))
+	assert.Equal(t, "videos", pluralize("video"))
+	assert.Equal(t, "photos", pluralize("photo"))
+	assert.Equal(t, "waltzes", pluralize("waltz"))
+	assert.Equal(t, "buzzes", pluralize("buzz"))
+	assert.Equal(t, "sheep", pluralize("sheep"))
+	assert.Equal(t, "", pluralize(""))
+}
+
+func TestCanExtendPluralizer(t *testing.T) {
+	pluralizer := NewPluralizer().AddIrregular("Cactus", "Cacti").AddUncountable("fish")
+	assert.Equal(t, "cacti", pluralizer.Pluralize("cactus"))
+	assert.Equal(t, "fish", pluralizer.Pluralize("fish"))
+	assert.Equal(t, "fishes", pluralize("fish"))
+
+	empty := &Pluralizer{}
+	assert.Equal(t, "geese", empty.AddIrregular("goose", "geese").Pluralize("goose"))
+	assert.Equal(t, "rice", empty.AddUncountable("rice").Pluralize("rice"))
+}
+
+func TestSplitWords(t *testing.T) {
+	assert.Equal(t, []string{"Order", "Item"}, splitWords("OrderItem"))
+	assert.Equal(t, []string{"HTTP", "Server"}, splitWords("HTTPServer"))
+	assert.Equal(t, []string{"user", "ID"}, splitWords("userID"))
+	assert.Equal(t, []string{"order", "item"}, splitWords("order_item"))
+	assert.Equal(t, []string{"order", "item"}, splitWords("order-item"))
+	assert.Equal(t, []string{"something1"}, splitWords("something1"))
+	assert.Empty(t, splitWords(""))
+}
+
+func TestFormatResourceName(t *testing.T) {
+	defer func(style CaseStyle) { ResourceNameCaseStyle = style }(ResourceNameCaseStyle)
+
+	expected := map[CaseStyle]string{
+		CaseStyleLower: "orderpeople",
+		CaseStyleKebab: "order-people",
+		CaseStyleSnake: "order_people",
+		CaseStyleCamel: "orderPeople",
+	}
+	for style, name := range expected {
+		ResourceNameCaseStyle = style
+		assert.Equal(t, name, formatResourceName("OrderPerson"), "style %s", style)
+	}
+	assert.Equal(t, "", formatResourceName(""))
+}
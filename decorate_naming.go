@@ -0,0 +1,226 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ResourceNamer describes types that give their own resource name
+//
+// The resource name is used as is in the URIs built by Decorate, DecorateWithURL, and DecorateCollection (e.g.: "people").
+type ResourceNamer interface {
+	ResourceName() string
+}
+
+// CaseStyle tells how the words of a resource name are joined
+type CaseStyle string
+
+const (
+	// CaseStyleLower joins the words in lowercase, without separator (e.g.: "orderitems")
+	CaseStyleLower CaseStyle = "lower"
+	// CaseStyleKebab joins the words in lowercase with dashes (e.g.: "order-items")
+	CaseStyleKebab CaseStyle = "kebab"
+	// CaseStyleSnake joins the words in lowercase with underscores (e.g.: "order_items")
+	CaseStyleSnake CaseStyle = "snake"
+	// CaseStyleCamel joins the words in camel case (e.g.: "orderItems")
+	CaseStyleCamel CaseStyle = "camel"
+)
+
+// ResourceNameCaseStyle is the CaseStyle of the resource names built from type names
+//
+// The default is CaseStyleLower.
+var ResourceNameCaseStyle = CaseStyleLower
+
+// Pluralizer gives the plural form of English words
+//
+// Words that are not in the Irregulars or Uncountables tables follow the regular English rules.
+// The tables are not protected against concurrent access, they should be extended when the program starts.
+type Pluralizer struct {
+	Irregulars   map[string]string
+	Uncountables map[string]bool
+}
+
+// DefaultPluralizer is the Pluralizer used to build resource names
+//
+// Example:
+//
+//	core.DefaultPluralizer.AddIrregular("cactus", "cacti").AddUncountable("fish")
+var DefaultPluralizer = NewPluralizer()
+
+// NewPluralizer creates a new Pluralizer with the common English irregular and uncountable words
+func NewPluralizer() *Pluralizer {
+	return &Pluralizer{
+		Irregulars: map[string]string{
+			"child":     "children",
+			"person":    "people",
+			"man":       "men",
+			"woman":     "women",
+			"mouse":     "mice",
+			"goose":     "geese",
+			"tooth":     "teeth",
+			"foot":      "feet",
+			"ox":        "oxen",
+			"criterion": "criteria",
+			"knife":     "knives",
+			"life":      "lives",
+			"wife":      "wives",
+			"thief":     "thieves",
+			"photo":     "photos",
+			"piano":     "pianos",
+			"memo":      "memos",
+			"logo":      "logos",
+			"demo":      "demos",
+			"repo":      "repos",
+			"promo":     "promos",
+		},
+		Uncountables: map[string]bool{
+			"deer":        true,
+			"equipment":   true,
+			"feedback":    true,
+			"information": true,
+			"metadata":    true,
+			"money":       true,
+			"news":        true,
+			"series":      true,
+			"sheep":       true,
+			"software":    true,
+			"species":     true,
+		},
+	}
+}
+
+// AddIrregular adds an irregular plural to the Pluralizer
+func (pluralizer *Pluralizer) AddIrregular(singular, plural string) *Pluralizer {
+	if pluralizer.Irregulars == nil {
+		pluralizer.Irregulars = map[string]string{}
+	}
+	pluralizer.Irregulars[strings.ToLower(singular)] = strings.ToLower(plural)
+	return pluralizer
+}
+
+// AddUncountable adds words that have no plural form to the Pluralizer
+func (pluralizer *Pluralizer) AddUncountable(words ...string) *Pluralizer {
+	if pluralizer.Uncountables == nil {
+		pluralizer.Uncountables = map[string]bool{}
+	}
+	for _, word := range words {
+		pluralizer.Uncountables[strings.ToLower(word)] = true
+	}
+	return pluralizer
+}
+
+// Pluralize returns the plural form of a word
+//
+// The case of the first letter of the word is kept for irregular plurals.
+func (pluralizer Pluralizer) Pluralize(word string) string {
+	lower := strings.ToLower(word)
+	if len(lower) == 0 || pluralizer.Uncountables[lower] {
+		return word
+	}
+	if plural, found := pluralizer.Irregulars[lower]; found {
+		if unicode.IsUpper([]rune(word)[0]) {
+			return strings.ToUpper(plural[:1]) + plural[1:]
+		}
+		return plural
+	}
+	switch {
+	case strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"), strings.HasSuffix(lower, "zz"):
+		return word + "es"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"):
+		return word + "es"
+	case strings.HasSuffix(lower, "z"):
+		if endsWithVowelAnd(lower, 'z') {
+			return word + "zes"
+		}
+		return word + "es"
+	case strings.HasSuffix(lower, "o"):
+		if endsWithVowelAnd(lower, 'o') {
+			return word + "s"
+		}
+		return word + "es"
+	case strings.HasSuffix(lower, "y"):
+		if endsWithVowelAnd(lower, 'y') {
+			return word + "s"
+		}
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(lower, "ff"), strings.HasSuffix(lower, "oof"), strings.HasSuffix(lower, "ief"), strings.HasSuffix(lower, "eef"):
+		return word + "s"
+	case strings.HasSuffix(lower, "f"):
+		return word[:len(word)-1] + "ves"
+	}
+	return word + "s"
+}
+
+// Format joins words with the CaseStyle
+func (style CaseStyle) Format(words ...string) string {
+	var builder strings.Builder
+	for i, word := range words {
+		word = strings.ToLower(word)
+		switch style {
+		case CaseStyleKebab:
+			if i > 0 {
+				builder.WriteString("-")
+			}
+		case CaseStyleSnake:
+			if i > 0 {
+				builder.WriteString("_")
+			}
+		case CaseStyleCamel:
+			if i > 0 && len(word) > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		builder.WriteString(word)
+	}
+	return builder.String()
+}
+
+// pluralize returns the plural form of a given string with the DefaultPluralizer
+func pluralize(name string) string {
+	return DefaultPluralizer.Pluralize(name)
+}
+
+// formatResourceName returns the resource name of a type name
+//
+// The last word of the type name is pluralized, and the words are joined with ResourceNameCaseStyle.
+// "OrderItem" gives "orderitems", "order-items", "order_items", or "orderItems".
+func formatResourceName(typeName string) string {
+	words := splitWords(typeName)
+	if len(words) == 0 {
+		return ""
+	}
+	words[len(words)-1] = pluralize(strings.ToLower(words[len(words)-1]))
+	return ResourceNameCaseStyle.Format(words...)
+}
+
+// splitWords splits a name in camel case, pascal case, snake case, or kebab case into words
+//
+// Acronyms are kept together: "HTTPServer" gives "HTTP" and "Server".
+func splitWords(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' || runes[i] == '-' || unicode.IsSpace(runes[i]) {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i > start && unicode.IsUpper(runes[i]) {
+			previousIsLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousIsLower || (unicode.IsUpper(runes[i-1]) && nextIsLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	return words
+}
+
+// endsWithVowelAnd tells if a word ends with a vowel followed by the given letter
+func endsWithVowelAnd(word string, letter byte) bool {
+	return len(word) > 1 && word[len(word)-1] == letter && strings.IndexByte("aeiou", word[len(word)-2]) >= 0
+}
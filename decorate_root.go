@@ -0,0 +1,88 @@
+package core
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// RootResolver gives the root of the selfURI of an item, as a path or as an absolute URL
+//
+// The selfURI is made of the root, the resource name, and the identifier of the item.
+// Resolvers are used for nested routes, where the root depends on the item:
+//
+//	resolver := func(item any) string {
+//		if user, ok := item.(User); ok {
+//			return "/api/v1/accounts/" + user.AccountID.String()
+//		}
+//		return "/api/v1"
+//	}
+//	core.DecorateWithResolver(user, resolver) // selfURI: /api/v1/accounts/{aid}/users/{id}
+type RootResolver func(item any) string
+
+// DecorateWithResolver decorates a struct that can be identified with a RootResolver
+//
+// The RootResolver is also used for the related resources of the fields of the struct, see Decorate.
+func DecorateWithResolver(item any, resolver RootResolver) *DecoratedResource {
+	return decorate(item, resolver.selfURI)
+}
+
+// RequestRootURL returns the absolute URL of a root path on the server a request was sent to
+//
+// The scheme and host come from the X-Forwarded-Proto, X-Forwarded-Host, and X-Forwarded-Port headers when present,
+// and X-Forwarded-Prefix is prepended to the root path.
+// These headers can be forged by clients, they should only be set by a trusted reverse proxy.
+//
+// Example:
+//
+//	root := core.RequestRootURL(r, "/api/v1")
+//	core.RespondWithJSON(w, http.StatusOK, core.DecorateWithURL(user, root))
+func RequestRootURL(r *http.Request, rootpath string) url.URL {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := forwardedHeader(r, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	host := r.Host
+	if forwardedHost := forwardedHeader(r, "X-Forwarded-Host"); len(forwardedHost) > 0 {
+		host = forwardedHost
+	}
+	if port := forwardedHeader(r, "X-Forwarded-Port"); len(port) > 0 && !strings.Contains(host, ":") {
+		if !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+			host += ":" + port
+		}
+	}
+	return url.URL{
+		Scheme: scheme,
+		Host:   host,
+		Path:   path.Join("/", forwardedHeader(r, "X-Forwarded-Prefix"), rootpath),
+	}
+}
+
+// RequestRootResolver returns a RootResolver that gives the absolute URL of a root path on the server a request was sent to
+//
+// See RequestRootURL
+func RequestRootResolver(r *http.Request, rootpath string) RootResolver {
+	root := RequestRootURL(r, rootpath)
+	return func(any) string {
+		return root.String()
+	}
+}
+
+// selfURI builds the selfURI of an item from the root given by the RootResolver
+func (resolver RootResolver) selfURI(item any, resourceName, id string) string {
+	root := resolver(item)
+	if rootURL, err := url.Parse(root); err == nil && rootURL.IsAbs() {
+		return rootURL.JoinPath(resourceName, id).String()
+	}
+	return path.Join(root, resourceName, id)
+}
+
+// forwardedHeader returns the first value of a X-Forwarded-* header, proxies append their values to the list
+func forwardedHeader(r *http.Request, name string) string {
+	value, _, _ := strings.Cut(r.Header.Get(name), ",")
+	return strings.TrimSpace(value)
+}
@@ -3,12 +3,15 @@ package core_test
 import (
 	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/gildas/go-core"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock Identifiable and StringIdentifiable types for testing
@@ -133,3 +136,521 @@ func TestMarshalJSON(t *testing.T) {
 	_, err = json.Marshal(core.Decorate(BogusValue{}, "/var/api"))
 	assert.Error(t, err)
 }
+
+func TestMarshalJSONWithNonObjectData(t *testing.T) {
+	data, err := json.Marshal(core.DecoratedResource{Data: []string{"a", "b"}, SelfURI: "/api/v1/letters"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data": ["a", "b"], "selfURI": "/api/v1/letters"}`, string(data))
+
+	data, err = json.Marshal(core.DecoratedResource{Data: 12, SelfURI: "/api/v1/count"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data": 12, "selfURI": "/api/v1/count"}`, string(data))
+
+	data, err = json.Marshal(core.DecoratedResource{Data: struct{}{}, SelfURI: "/api/v1/empty"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"selfURI": "/api/v1/empty"}`, string(data))
+}
+
+func TestMarshalJSONWithLinksAndEmbedded(t *testing.T) {
+	owner := core.Decorate(MockStringIdentifiable{ID: "john"}, "/api/v1")
+	decorated := core.Decorate(MockStringIdentifiable{ID: "abc"}, "/api/v1").
+		AddLink("owner", owner.SelfURI).
+		AddTemplatedLink("search", "/api/v1/mockstringidentifiables{?name}").
+		AddEmbedded("owner", owner)
+
+	data, err := json.Marshal(decorated)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ID": "abc",
+		"selfURI": "/api/v1/mockstringidentifiables/abc",
+		"links": {
+			"owner": {"href": "/api/v1/mockstringidentifiables/john"},
+			"search": {"href": "/api/v1/mockstringidentifiables{?name}", "templated": true}
+		},
+		"embedded": {
+			"owner": {"ID": "john", "selfURI": "/api/v1/mockstringidentifiables/john"}
+		}
+	}`, string(data))
+}
+
+func TestMarshalHAL(t *testing.T) {
+	decorated := core.Decorate(MockStringIdentifiable{ID: "abc"}, "/api/v1").
+		AddTemplatedLink("search", "/api/v1/mockstringidentifiables{?name}").
+		AddEmbedded("friends", core.DecorateAll([]MockStringIdentifiable{{ID: "john"}, {ID: "jane"}}, "/api/v1"))
+	decorated.Format = core.DecorationFormatHAL
+
+	data, err := json.Marshal(decorated)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ID": "abc",
+		"_links": {
+			"self": {"href": "/api/v1/mockstringidentifiables/abc"},
+			"search": {"href": "/api/v1/mockstringidentifiables{?name}", "templated": true}
+		},
+		"_embedded": {
+			"friends": [
+				{"ID": "john", "_links": {"self": {"href": "/api/v1/mockstringidentifiables/john"}}},
+				{"ID": "jane", "_links": {"self": {"href": "/api/v1/mockstringidentifiables/jane"}}}
+			]
+		}
+	}`, string(data))
+}
+
+func TestMarshalJSONAPI(t *testing.T) {
+	owner := MockIdentifiable{ID: uuid.MustParse("11111111-2222-3333-4444-555555555555")}
+	decorated := core.Decorate(MockStringIdentifiable{ID: "abc"}, "/api/v1").
+		AddLink("archive", "/api/v1/archives/abc").
+		AddEmbedded("owner", core.Decorate(owner, "/api/v1")).
+		AddEmbedded("tags", []MockStringIdentifiable{{ID: "red"}}).
+		AddEmbedded("stats", map[string]int{"views": 3})
+	decorated.Format = core.DecorationFormatJSONAPI
+
+	data, err := json.Marshal(decorated)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"data": {
+			"type": "mockstringidentifiables",
+			"id": "abc",
+			"attributes": {"stats": {"views": 3}},
+			"links": {"self": "/api/v1/mockstringidentifiables/abc", "archive": "/api/v1/archives/abc"},
+			"relationships": {
+				"owner": {
+					"data": {"type": "mockidentifiables", "id": "11111111-2222-3333-4444-555555555555"},
+					"links": {"related": "/api/v1/mockidentifiables/11111111-2222-3333-4444-555555555555"}
+				},
+				"tags": {"data": [{"type": "mockstringidentifiables", "id": "red"}]}
+			}
+		},
+		"included": [
+			{"type": "mockidentifiables", "id": "11111111-2222-3333-4444-555555555555", "links": {"self": "/api/v1/mockidentifiables/11111111-2222-3333-4444-555555555555"}},
+			{"type": "mockstringidentifiables", "id": "red"}
+		]
+	}`, string(data))
+}
+
+func TestShouldFailMarshalingDecoratedFormatsWithBogusValue(t *testing.T) {
+	for _, format := range []core.DecorationFormat{core.DecorationFormatDefault, core.DecorationFormatHAL, core.DecorationFormatJSONAPI} {
+		decorated := core.DecoratedResource{Data: BogusValue{}, Format: format}
+		_, err := json.Marshal(decorated)
+		assert.Errorf(t, err, "Format %s should fail", format)
+
+		decorated = core.DecoratedResource{Data: MockStringIdentifiable{ID: "abc"}, Format: format}
+		decorated.AddEmbedded("bogus", core.DecoratedResource{Data: BogusValue{}})
+		_, err = json.Marshal(decorated)
+		assert.Errorf(t, err, "Format %s should fail with bogus embedded", format)
+	}
+}
+
+func TestDecorateCollection(t *testing.T) {
+	items := []MockStringIdentifiable{{ID: "123"}, {ID: "456"}}
+	collection := core.DecorateCollection(items, "/api/v1").AddPageLinks(2, 2, 7)
+	assert.Equal(t, "/api/v1/mockstringidentifiables", collection.SelfURI)
+	assert.Equal(t, 2, collection.Count)
+	assert.Equal(t, 7, collection.Total)
+	assert.Equal(t, []string{"/api/v1/mockstringidentifiables/123", "/api/v1/mockstringidentifiables/456"}, collection.SelfURIs)
+	assert.Equal(t, "/api/v1/mockstringidentifiables?limit=2&offset=0", collection.Links["first"].Href)
+	assert.Equal(t, "/api/v1/mockstringidentifiables?limit=2&offset=0", collection.Links["prev"].Href)
+	assert.Equal(t, "/api/v1/mockstringidentifiables?limit=2&offset=4", collection.Links["next"].Href)
+	assert.Equal(t, "/api/v1/mockstringidentifiables?limit=2&offset=6", collection.Links["last"].Href)
+
+	last := core.DecorateCollection(items[:1], "/api/v1").AddPageLinks(6, 2, 7)
+	assert.NotContains(t, last.Links, "next")
+	assert.Contains(t, last.Links, "prev")
+
+	t.Run("TypeCarrier", func(t *testing.T) {
+		collection := core.DecorateCollection([]MockIdentifiable{}, "/api/v1")
+		assert.Equal(t, "/api/v1/mockidentifiables", collection.SelfURI)
+		assert.Equal(t, 0, collection.Total)
+	})
+}
+
+func TestMarshalDecoratedCollection(t *testing.T) {
+	items := []MockStringIdentifiable{{ID: "123"}, {ID: "456"}}
+	collection := core.DecorateCollection(items, "/api/v1").AddLink("next", "/api/v1/mockstringidentifiables?cursor=abc")
+	expectedJSON := `{
+		"selfURI": "/api/v1/mockstringidentifiables",
+		"count": 2,
+		"total": 2,
+		"links": {"next": {"href": "/api/v1/mockstringidentifiables?cursor=abc"}},
+		"items": [
+			{"ID": "123", "selfURI": "/api/v1/mockstringidentifiables/123"},
+			{"ID": "456", "selfURI": "/api/v1/mockstringidentifiables/456"}
+		]
+	}`
+
+	payload, err := json.Marshal(collection)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expectedJSON, string(payload))
+
+	var unmarshaled core.DecoratedCollection[MockStringIdentifiable]
+	err = json.Unmarshal(payload, &unmarshaled)
+	assert.NoError(t, err)
+	assert.Equal(t, items, unmarshaled.Items)
+	assert.Equal(t, collection.SelfURIs, unmarshaled.SelfURIs)
+	assert.Equal(t, collection.SelfURI, unmarshaled.SelfURI)
+	assert.Equal(t, 2, unmarshaled.Count)
+	assert.Equal(t, "/api/v1/mockstringidentifiables?cursor=abc", unmarshaled.Links["next"].Href)
+
+	_, err = json.Marshal(core.DecorateCollection([]BogusValue{{}}, "/api/v1"))
+	assert.Error(t, err)
+}
+
+func TestUnmarshalDecoratedCollectionWithNonObjectData(t *testing.T) {
+	payload := `{"count":2,"total":2,"items":[{"data":12,"selfURI":""},{"data":34,"selfURI":""}]}`
+	var collection core.DecoratedCollection[int]
+	err := json.Unmarshal([]byte(payload), &collection)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{12, 34}, collection.Items)
+
+	err = json.Unmarshal([]byte(`{"items":[{"data":"twelve"}]}`), &collection)
+	assert.Error(t, err)
+}
+
+// MockAnimal is an interface implemented by TypeCarrier types for testing
+type MockAnimal interface {
+	core.TypeCarrier
+	GetName() string
+}
+
+type MockDog struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (dog MockDog) GetType() string {
+	return "dog"
+}
+
+func (dog MockDog) GetID() string {
+	return dog.ID
+}
+
+func (dog MockDog) GetName() string {
+	return dog.Name
+}
+
+func TestUnmarshalDecorated(t *testing.T) {
+	t.Run("Decorate", func(t *testing.T) {
+		item := MockIdentifiable{ID: uuid.New()}
+		payload, err := json.Marshal(core.Decorate(item, "/api/v1").AddLink("owner", "/api/v1/users/1"))
+		assert.NoError(t, err)
+
+		var decorated core.Decorated[MockIdentifiable]
+		err = json.Unmarshal(payload, &decorated)
+		assert.NoError(t, err)
+		assert.Equal(t, item, decorated.Data)
+		assert.Equal(t, "/api/v1/mockidentifiables/"+item.ID.String(), decorated.SelfURI)
+		assert.Equal(t, "/api/v1/users/1", decorated.Links["owner"].Href)
+
+		roundtrip, err := json.Marshal(decorated)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(payload), string(roundtrip))
+	})
+
+	t.Run("DecorateWithURL", func(t *testing.T) {
+		item := MockStringIdentifiable{ID: "abc"}
+		root := core.Must(url.Parse("http://localhost:8080/api/v1"))
+		payload, err := json.Marshal(core.DecorateWithURL(item, *root))
+		assert.NoError(t, err)
+
+		var decorated core.Decorated[*MockStringIdentifiable]
+		err = json.Unmarshal(payload, &decorated)
+		assert.NoError(t, err)
+		assert.Equal(t, &item, decorated.Data)
+		assert.Equal(t, "http://localhost:8080/api/v1/mockstringidentifiables/abc", decorated.SelfURI)
+	})
+
+	t.Run("NonObjectData", func(t *testing.T) {
+		var decorated core.Decorated[core.Time]
+		err := json.Unmarshal([]byte(`{"data":"2024-03-01T10:00:00Z","selfURI":"/api/v1/times/1"}`), &decorated)
+		assert.NoError(t, err)
+		assert.Equal(t, 2024, decorated.Data.AsTime().Year())
+		assert.Equal(t, "/api/v1/times/1", decorated.SelfURI)
+	})
+
+	t.Run("Embedded", func(t *testing.T) {
+		payload := `{"ID":"abc","selfURI":"/api/v1/mockstringidentifiables/abc","embedded":{"owner":{"ID":"123","selfURI":"/api/v1/mockstringidentifiables/123"}}}`
+		var decorated core.Decorated[MockStringIdentifiable]
+		err := json.Unmarshal([]byte(payload), &decorated)
+		assert.NoError(t, err)
+		assert.Contains(t, decorated.Embedded, "owner")
+
+		var owner core.Decorated[MockStringIdentifiable]
+		err = json.Unmarshal(decorated.Embedded["owner"], &owner)
+		assert.NoError(t, err)
+		assert.Equal(t, "123", owner.Data.ID)
+
+		roundtrip, err := json.Marshal(decorated)
+		assert.NoError(t, err)
+		assert.JSONEq(t, payload, string(roundtrip))
+	})
+
+	t.Run("Interface", func(t *testing.T) {
+		payload, err := json.Marshal(core.Decorate(MockDog{Type: "dog", ID: "rex", Name: "Rex"}, "/api/v1"))
+		assert.NoError(t, err)
+
+		decorated := core.Decorated[MockAnimal]{Registry: core.TypeRegistry{}.Add(MockDog{})}
+		err = json.Unmarshal(payload, &decorated)
+		assert.NoError(t, err)
+		assert.IsType(t, &MockDog{}, decorated.Data)
+		assert.Equal(t, "Rex", decorated.Data.GetName())
+		assert.Equal(t, "/api/v1/dogs/rex", decorated.SelfURI)
+
+		collection := core.DecoratedCollection[MockAnimal]{Registry: core.CaseInsensitiveTypeRegistry{}.Add(MockDog{})}
+		payload, err = json.Marshal(core.DecorateCollection([]MockAnimal{MockDog{Type: "dog", ID: "rex", Name: "Rex"}}, "/api/v1"))
+		assert.NoError(t, err)
+		err = json.Unmarshal(payload, &collection)
+		assert.NoError(t, err)
+		assert.Len(t, collection.Items, 1)
+		assert.Equal(t, "Rex", collection.Items[0].GetName())
+		assert.Equal(t, "/api/v1/dogs", collection.SelfURI)
+	})
+
+	t.Run("ShouldFailWithUnknownType", func(t *testing.T) {
+		decorated := core.Decorated[MockAnimal]{Registry: core.TypeRegistry{}.Add(MockDog{})}
+		err := json.Unmarshal([]byte(`{"type":"cat","selfURI":"/api/v1/cats/1"}`), &decorated)
+		assert.Error(t, err)
+	})
+}
+
+type MockPerson struct {
+	ID string
+}
+
+func (person MockPerson) GetID() string {
+	return person.ID
+}
+
+func (person MockPerson) ResourceName() string {
+	return "staff"
+}
+
+type OrderItem struct {
+	ID string
+}
+
+func (item OrderItem) GetID() string {
+	return item.ID
+}
+
+func TestDecorateWithResourceNamer(t *testing.T) {
+	decorated := core.Decorate(MockPerson{ID: "123"}, "/api/v1")
+	assert.Equal(t, "/api/v1/staff/123", decorated.SelfURI)
+	assert.Equal(t, "staff", decorated.ResourceName())
+	assert.Equal(t, "/api/v1/staff", core.DecorateCollection([]MockPerson{}, "/api/v1").SelfURI)
+}
+
+func TestDecorateWithCaseStyle(t *testing.T) {
+	defer func(style core.CaseStyle) { core.ResourceNameCaseStyle = style }(core.ResourceNameCaseStyle)
+
+	assert.Equal(t, "/api/v1/orderitems/1", core.Decorate(OrderItem{ID: "1"}, "/api/v1").SelfURI)
+	core.ResourceNameCaseStyle = core.CaseStyleKebab
+	assert.Equal(t, "/api/v1/order-items/1", core.Decorate(OrderItem{ID: "1"}, "/api/v1").SelfURI)
+	core.ResourceNameCaseStyle = core.CaseStyleSnake
+	assert.Equal(t, "order_items", core.Decorate(OrderItem{ID: "1"}, "/api/v1").ResourceName())
+	core.ResourceNameCaseStyle = core.CaseStyleCamel
+	assert.Equal(t, "/api/v1/orderItems", core.DecorateCollection([]OrderItem{}, "/api/v1").SelfURI)
+}
+
+type MockOrder struct {
+	ID       string                   `json:"id"`
+	Customer MockStringIdentifiable   `json:"customer" core:"link,ref"`
+	Seller   *MockStringIdentifiable  `json:"seller" core:"ref"`
+	Items    []MockStringIdentifiable `json:"items" core:"embed"`
+	Tags     []MockStringIdentifiable `json:"tags" core:"ref"`
+	Note     string                   `json:"note" core:"ref"`
+}
+
+func (order MockOrder) GetID() string {
+	return order.ID
+}
+
+func TestDecorateWithTaggedFields(t *testing.T) {
+	order := MockOrder{
+		ID:       "o1",
+		Customer: MockStringIdentifiable{ID: "c1"},
+		Items:    []MockStringIdentifiable{{ID: "i1"}},
+		Tags:     []MockStringIdentifiable{{ID: "t1"}, {ID: "t2"}},
+		Note:     "hello",
+	}
+	decorated := core.Decorate(order, "/api/v1")
+	assert.Equal(t, "/api/v1/mockorders/o1", decorated.SelfURI)
+	assert.Equal(t, "/api/v1/mockstringidentifiables/c1", decorated.Links["customer"].Href)
+	assert.Contains(t, decorated.Embedded, "items")
+	assert.Equal(t, order, decorated.Data, "the data should not be modified")
+
+	expectedJSON := `{
+		"id": "o1",
+		"customer": {"id": "c1", "selfURI": "/api/v1/mockstringidentifiables/c1"},
+		"seller": null,
+		"tags": [
+			{"id": "t1", "selfURI": "/api/v1/mockstringidentifiables/t1"},
+			{"id": "t2", "selfURI": "/api/v1/mockstringidentifiables/t2"}
+		],
+		"note": "hello",
+		"selfURI": "/api/v1/mockorders/o1",
+		"links": {"customer": {"href": "/api/v1/mockstringidentifiables/c1"}},
+		"embedded": {"items": [{"ID": "i1", "selfURI": "/api/v1/mockstringidentifiables/i1"}]}
+	}`
+	payload, err := json.Marshal(decorated)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expectedJSON, string(payload))
+
+	t.Run("Pointer", func(t *testing.T) {
+		order := &MockOrder{ID: "o2", Seller: &MockStringIdentifiable{ID: "s1"}}
+		root := core.Must(url.Parse("https://example.com/api/v1"))
+		payload, err := json.Marshal(core.DecorateWithURL(order, *root))
+		assert.NoError(t, err)
+
+		var members map[string]any
+		assert.NoError(t, json.Unmarshal(payload, &members))
+		assert.Equal(t, map[string]any{"id": "s1", "selfURI": "https://example.com/api/v1/mockstringidentifiables/s1"}, members["seller"])
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		payload, err := json.Marshal(core.DecorateCollection([]MockOrder{order}, "/api/v1"))
+		assert.NoError(t, err)
+
+		var collection core.DecoratedCollection[map[string]any]
+		assert.NoError(t, json.Unmarshal(payload, &collection))
+		assert.Equal(t, map[string]any{"id": "c1", "selfURI": "/api/v1/mockstringidentifiables/c1"}, collection.Items[0]["customer"])
+		assert.NotContains(t, collection.Items[0], "items")
+	})
+}
+
+type MockNode struct {
+	ID       string      `json:"id"`
+	Parent   *MockNode   `json:"parent,omitempty" core:"embed"`
+	Children []*MockNode `json:"children,omitempty" core:"embed"`
+}
+
+func (node MockNode) GetID() string {
+	return node.ID
+}
+
+func TestDecorateWithCyclicTaggedFields(t *testing.T) {
+	// The related resources were loaded separately, they are not the same values
+	root := MockNode{ID: "root", Children: []*MockNode{{ID: "child", Parent: &MockNode{ID: "root"}}}}
+	decorated := core.Decorate(root, "/api/v1")
+	payload, err := json.Marshal(decorated)
+	require.NoError(t, err)
+
+	expectedJSON := `{
+		"id": "root",
+		"selfURI": "/api/v1/mocknodes/root",
+		"embedded": {"children": [{
+			"id": "child",
+			"parent": {"id": "root", "selfURI": "/api/v1/mocknodes/root"},
+			"selfURI": "/api/v1/mocknodes/child",
+			"embedded": {"children": []}
+		}]}
+	}`
+	assert.JSONEq(t, expectedJSON, string(payload))
+
+	// The related resources point to each other
+	parent := &MockNode{ID: "root"}
+	child := &MockNode{ID: "child", Parent: parent}
+	parent.Children = []*MockNode{child}
+	decorated = core.Decorate(parent, "/api/v1")
+	require.Contains(t, decorated.Embedded, "children")
+	children, ok := decorated.Embedded["children"].([]core.DecoratedResource)
+	require.True(t, ok, "children should be decorated resources")
+	require.Len(t, children, 1)
+	assert.Equal(t, "/api/v1/mocknodes/child", children[0].SelfURI)
+	assert.NotContains(t, children[0].Embedded, "parent", "the parent should be referenced, not embedded")
+}
+
+type Box[T any] struct {
+	ID    string
+	Value T
+}
+
+func (box Box[T]) GetID() string {
+	return box.ID
+}
+
+func TestDecorateResourceNames(t *testing.T) {
+	t.Run("Pointer", func(t *testing.T) {
+		decorated := core.Decorate(&MockStringIdentifiable{ID: "abc"}, "/api/v1")
+		assert.Equal(t, "/api/v1/mockstringidentifiables/abc", decorated.SelfURI)
+		assert.Equal(t, "mockstringidentifiables", decorated.ResourceName())
+	})
+
+	t.Run("Generic", func(t *testing.T) {
+		decorated := core.Decorate(Box[int]{ID: "1"}, "/api/v1")
+		assert.Equal(t, "/api/v1/boxes/1", decorated.SelfURI)
+		assert.Equal(t, "/api/v1/boxes", core.DecorateCollection([]*Box[MockStringIdentifiable]{}, "/api/v1").SelfURI)
+	})
+
+	t.Run("TypeCarrier", func(t *testing.T) {
+		dog := &MockDog{ID: "rex"}
+		assert.Equal(t, "/api/v1/dogs/rex", core.Decorate(dog, "/api/v1").SelfURI)
+		assert.Equal(t, "/api/v1/dogs", core.DecorateCollection([]*MockDog{dog}, "/api/v1").SelfURI)
+		assert.Equal(t, "/api/v1/dogs", core.DecorateCollection([]MockDog{}, "/api/v1").SelfURI)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		decorated := core.Decorate(nil, "/api/v1")
+		assert.Empty(t, decorated.SelfURI)
+		assert.Empty(t, decorated.ResourceName())
+		var dog *MockDog
+		assert.Equal(t, "mockdogs", core.DecoratedResource{Data: dog}.ResourceName())
+	})
+
+	t.Run("NilPointer", func(t *testing.T) {
+		var dog *MockDog
+		assert.NotPanics(t, func() {
+			assert.Empty(t, core.Decorate(dog, "/api/v1").SelfURI)
+		})
+		assert.NotPanics(t, func() {
+			decorated := core.DecorateAll([]*MockDog{{ID: "rex"}, nil}, "/api/v1")
+			require.Len(t, decorated, 2)
+			assert.Equal(t, "/api/v1/dogs/rex", decorated[0].SelfURI)
+			assert.Empty(t, decorated[1].SelfURI)
+		})
+		assert.NotPanics(t, func() {
+			assert.Equal(t, "/api/v1/dogs", core.DecorateCollection([]*MockDog{nil}, "/api/v1").SelfURI)
+		})
+		var person *MockStringIdentifiable
+		assert.NotPanics(t, func() {
+			assert.Empty(t, core.Decorate(person, "/api/v1").SelfURI)
+		})
+	})
+}
+
+func TestDecorateWithResolver(t *testing.T) {
+	resolver := func(item any) string {
+		if dog, ok := item.(MockDog); ok {
+			return "/api/v1/owners/" + dog.Name
+		}
+		return "https://example.com/api/v1"
+	}
+	decorated := core.DecorateWithResolver(MockDog{ID: "rex", Name: "john"}, resolver)
+	assert.Equal(t, "/api/v1/owners/john/dogs/rex", decorated.SelfURI)
+
+	decorated = core.DecorateWithResolver(MockOrder{ID: "o1", Customer: MockStringIdentifiable{ID: "c1"}}, resolver)
+	assert.Equal(t, "https://example.com/api/v1/mockorders/o1", decorated.SelfURI)
+	assert.Equal(t, "https://example.com/api/v1/mockstringidentifiables/c1", decorated.Links["customer"].Href)
+}
+
+func TestRequestRootURL(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://internal:8080/api/v1/users", nil)
+	root := core.RequestRootURL(request, "/api/v1")
+	assert.Equal(t, "http://internal:8080/api/v1", root.String())
+
+	request.Header.Set("X-Forwarded-Proto", "https")
+	request.Header.Set("X-Forwarded-Host", "api.example.com, proxy.local")
+	request.Header.Set("X-Forwarded-Prefix", "/public")
+	root = core.RequestRootURL(request, "/api/v1")
+	assert.Equal(t, "https://api.example.com/public/api/v1", root.String())
+
+	request.Header.Set("X-Forwarded-Port", "8443")
+	decorated := core.DecorateWithResolver(MockStringIdentifiable{ID: "abc"}, core.RequestRootResolver(request, "/api/v1"))
+	assert.Equal(t, "https://api.example.com:8443/public/api/v1/mockstringidentifiables/abc", decorated.SelfURI)
+
+	request.Header.Set("X-Forwarded-Port", "443")
+	request.Header.Set("X-Forwarded-Proto", "javascript")
+	root = core.RequestRootURL(request, "")
+	assert.Equal(t, "http://api.example.com:443/public", root.String())
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Decorated is a resource decorated by Decorate or DecorateWithURL, with its typed data
+//
+// It is meant for the Go clients of APIs that send decorated resources:
+//
+//	var user core.Decorated[User]
+//	err := json.Unmarshal(payload, &user)
+//	fmt.Println(user.Data.Name, user.SelfURI)
+//
+// When T is an interface, the Registry must be set before unmarshaling so the data gets its concrete type:
+//
+//	animal := core.Decorated[Animal]{Registry: core.TypeRegistry{}.Add(Dog{}, Cat{})}
+//	err := json.Unmarshal(payload, &animal)
+type Decorated[T any] struct {
+	Data     T
+	SelfURI  string
+	Links    map[string]Link
+	Embedded map[string]json.RawMessage
+	Registry TypeUnmarshaler
+}
+
+// TypeUnmarshaler describes types that unmarshal TypeCarrier objects into their concrete type
+//
+// TypeRegistry and CaseInsensitiveTypeRegistry are TypeUnmarshalers.
+type TypeUnmarshaler interface {
+	// UnmarshalTyped unmarshals a payload into the type given by its typetag
+	UnmarshalTyped(payload []byte, typetag ...string) (any, error)
+}
+
+// AsDecoratedResource returns the DecoratedResource of this Decorated
+func (decorated Decorated[T]) AsDecoratedResource() *DecoratedResource {
+	resource := &DecoratedResource{Data: decorated.Data, SelfURI: decorated.SelfURI, Links: decorated.Links}
+	for rel, embedded := range decorated.Embedded {
+		resource.AddEmbedded(rel, embedded)
+	}
+	return resource
+}
+
+// MarshalJSON marshals the Decorated to JSON, like DecoratedResource does
+//
+// implements the json.Marshaler interface
+func (decorated Decorated[T]) MarshalJSON() ([]byte, error) {
+	return decorated.AsDecoratedResource().MarshalJSON()
+}
+
+// UnmarshalJSON unmarshals a resource decorated with DecorationFormatDefault
+//
+// The members of the data are unmarshaled into Data, the "selfURI", "links" and "embedded" members are captured.
+// Data that is not a JSON object is read from the "data" member.
+//
+// implements the json.Unmarshaler interface
+func (decorated *Decorated[T]) UnmarshalJSON(payload []byte) error {
+	var data T
+	decoration, err := unmarshalDecoratedData(payload, &data, decorated.Registry)
+	if err != nil {
+		return err
+	}
+	decorated.Data = data
+	decorated.SelfURI = decoration.SelfURI
+	decorated.Links = decoration.Links
+	decorated.Embedded = decoration.Embedded
+	return nil
+}
+
+// decoration contains the members added by DecorationFormatDefault to a resource
+type decoration struct {
+	SelfURI  string                     `json:"selfURI"`
+	Links    map[string]Link            `json:"links"`
+	Embedded map[string]json.RawMessage `json:"embedded"`
+}
+
+// unmarshalDecoratedData unmarshals a resource marshaled with DecorationFormatDefault into target, and returns its decoration
+//
+// If target does not unmarshal from a JSON object, the data is read from the "data" member.
+// If target points to an interface and registry is not nil, the registry gives the concrete type of the data.
+func unmarshalDecoratedData(payload []byte, target any, registry TypeUnmarshaler) (decoration decoration, err error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) {
+		return decoration, json.Unmarshal(payload, target)
+	}
+	if err = json.Unmarshal(payload, &decoration); err != nil {
+		return decoration, err
+	}
+	members := map[string]json.RawMessage{}
+	if err = json.Unmarshal(payload, &members); err != nil {
+		return decoration, err
+	}
+	delete(members, "selfURI")
+	delete(members, "links")
+	delete(members, "embedded")
+	if data, found := members["data"]; found && len(members) == 1 && !unmarshalsFromObject(target) {
+		return decoration, json.Unmarshal(data, target)
+	}
+	if payload, err = json.Marshal(members); err != nil {
+		return decoration, err
+	}
+	if value := reflect.ValueOf(target).Elem(); value.Kind() == reflect.Interface && registry != nil {
+		return decoration, unmarshalRegisteredType(payload, value, registry)
+	}
+	return decoration, json.Unmarshal(payload, target)
+}
+
+// unmarshalRegisteredType unmarshals a TypeCarrier with a registry and stores it in an interface value
+//
+// The registry gives a pointer to the data, the data itself is stored if the pointer does not implement the interface.
+func unmarshalRegisteredType(payload []byte, target reflect.Value, registry TypeUnmarshaler) error {
+	object, err := registry.UnmarshalTyped(payload)
+	if err != nil {
+		return err
+	}
+	value := reflect.ValueOf(object)
+	if !value.Type().AssignableTo(target.Type()) && value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if !value.Type().AssignableTo(target.Type()) {
+		return fmt.Errorf("%s does not implement %s", value.Type(), target.Type())
+	}
+	target.Set(value)
+	return nil
+}
+
+// unmarshalsFromObject tells if the value pointed by target is unmarshaled from a JSON object
+//
+// Types with their own json.Unmarshaler (like Time or URL) are expected to be given in the "data" member.
+func unmarshalsFromObject(target any) bool {
+	targetType := reflect.TypeOf(target)
+	for targetType.Kind() == reflect.Pointer {
+		targetType = targetType.Elem()
+	}
+	switch targetType.Kind() {
+	case reflect.Map, reflect.Interface:
+		return true
+	case reflect.Struct:
+		return !reflect.PointerTo(targetType).Implements(jsonUnmarshalerType)
+	}
+	return false
+}
@@ -16,8 +16,10 @@ import (
 // if not present, the fallback value is used
 func GetEnvAsString(name, fallback string) string {
 	if value, ok := os.LookupEnv(name); ok && len(value) > 0 {
+		recordEnvRead(name, value, true)
 		return value
 	}
+	recordEnvRead(name, fallback, false)
 	return fallback
 }
 
@@ -26,8 +28,11 @@ func GetEnvAsString(name, fallback string) string {
 // if not present, the fallback value is used
 func GetEnvAsBool(name string, fallback bool) bool {
 	if value, ok := os.LookupEnv(name); ok && len(value) > 0 {
-		return strings.Contains("1onyestrue", strings.ToLower(value))
+		result := strings.Contains("1onyestrue", strings.ToLower(value))
+		recordEnvRead(name, result, true)
+		return result
 	}
+	recordEnvRead(name, fallback, false)
 	return fallback
 }
 
@@ -37,9 +42,11 @@ func GetEnvAsBool(name string, fallback bool) bool {
 func GetEnvAsInt(name string, fallback int) int {
 	if value, ok := os.LookupEnv(name); ok && len(value) > 0 {
 		if intvalue, err := strconv.Atoi(value); err == nil {
+			recordEnvRead(name, intvalue, true)
 			return intvalue
 		}
 	}
+	recordEnvRead(name, fallback, false)
 	return fallback
 }
 
@@ -49,9 +56,11 @@ func GetEnvAsInt(name string, fallback int) int {
 func GetEnvAsTime(name string, fallback time.Time) time.Time {
 	if value, ok := os.LookupEnv(name); ok && len(value) > 0 {
 		if timevalue, err := time.Parse(time.RFC3339, value); err == nil {
+			recordEnvRead(name, timevalue, true)
 			return timevalue
 		}
 	}
+	recordEnvRead(name, fallback, false)
 	return fallback
 }
 
@@ -61,9 +70,11 @@ func GetEnvAsTime(name string, fallback time.Time) time.Time {
 func GetEnvAsDuration(name string, fallback time.Duration) time.Duration {
 	if value, ok := os.LookupEnv(name); ok && len(value) > 0 {
 		if duration, err := ParseDuration(value); err == nil {
+			recordEnvRead(name, duration, true)
 			return duration
 		}
 	}
+	recordEnvRead(name, fallback, false)
 	return fallback
 }
 
@@ -73,17 +84,21 @@ func GetEnvAsDuration(name string, fallback time.Duration) time.Duration {
 func GetEnvAsURL(name string, fallback any) *url.URL {
 	if value, ok := os.LookupEnv(name); ok && len(value) > 0 {
 		if address, err := url.Parse(value); err == nil {
+			recordEnvRead(name, address, true)
 			return address
 		}
 	}
 	if address, ok := fallback.(*url.URL); ok {
+		recordEnvRead(name, address, false)
 		return address
 	}
 	if address, ok := fallback.(url.URL); ok {
+		recordEnvRead(name, &address, false)
 		return &address
 	}
 	if value, ok := fallback.(string); ok {
 		if address, err := url.Parse(value); err == nil {
+			recordEnvRead(name, address, false)
 			return address
 		}
 	}
@@ -93,8 +108,10 @@ func GetEnvAsURL(name string, fallback any) *url.URL {
 func GetEnvAsUUID(name string, fallback uuid.UUID) uuid.UUID {
 	if value, ok := os.LookupEnv(name); ok && len(value) > 0 {
 		if uuid, err := uuid.Parse(value); err == nil {
+			recordEnvRead(name, uuid, true)
 			return uuid
 		}
 	}
+	recordEnvRead(name, fallback, false)
 	return fallback
 }
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// walkErrors visits an error and the errors it wraps, depth first
+//
+// The walk stops when visit returns false. Errors joined with errors.Join are all visited.
+func walkErrors(err error, visit func(err error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !visit(err) {
+		return false
+	}
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkErrors(wrapper.Unwrap(), visit)
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapper.Unwrap() {
+			if !walkErrors(inner, visit) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// errorProperties collects the ID, What, and Value fields of an error and the errors it wraps
+//
+// When more than one error carries the same field, the outermost one wins.
+func errorProperties(err error) map[string]string {
+	props := map[string]string{}
+	walkErrors(err, func(err error) bool {
+		errValue := reflect.ValueOf(err)
+
+		// detect errors like fmt.Errorf()
+		if errValue.Kind() == reflect.Pointer {
+			if errValue.IsNil() {
+				return true
+			}
+			errValue = errValue.Elem()
+		}
+		if errValue.Kind() != reflect.Struct {
+			return true
+		}
+
+		if _, found := props["id"]; !found {
+			if field := errValue.FieldByName("ID"); field.IsValid() && field.Kind() == reflect.String && field.Len() > 0 {
+				props["id"] = field.String()
+			}
+		}
+		if _, found := props["what"]; !found {
+			if field := errValue.FieldByName("What"); field.IsValid() && field.Kind() == reflect.String && field.Len() > 0 {
+				props["what"] = field.String()
+			}
+		}
+		if _, found := props["value"]; !found {
+			if field := errValue.FieldByName("Value"); field.IsValid() && field.CanInterface() {
+				if stringer, ok := field.Interface().(interface{ String() string }); ok {
+					props["value"] = stringer.String()
+				} else if identifiable, ok := field.Interface().(interface{ GetID() uuid.UUID }); ok {
+					props["value"] = identifiable.GetID().String()
+				} else if field.Kind() == reflect.String && field.Len() > 0 {
+					props["value"] = field.String()
+				}
+			}
+		}
+		return true
+	})
+	return props
+}
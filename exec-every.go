@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"time"
 )
 
@@ -17,29 +18,47 @@ import (
 // the invoked func can modify the interval by piping a new Duration to its given chan
 //
 // the func is executed once before the ticker starts
+//
+// tick counts the runs of the ticker, the runs forced on demand get the tick of the last run of the ticker
+//
+// ExecEvery panics if every is not positive.
+//
+// ExecEvery runs on a Job, which is easier to stop and offers more control.
 func ExecEvery(job func(tick int64, at time.Time, changeme chan time.Duration), every time.Duration) (chan bool, chan bool, chan time.Duration) {
+	return ExecEveryWithClock(RealClock{}, job, every)
+}
+
+// ExecEveryWithClock runs a func as a go routine regularly, ticking with the given Clock
+//
+// See ExecEvery.
+func ExecEveryWithClock(clock Clock, job func(tick int64, at time.Time, changeme chan time.Duration), every time.Duration) (chan bool, chan bool, chan time.Duration) {
 	changeme := make(chan time.Duration)
 	pingme := make(chan bool)
 	stopme := make(chan bool)
+	tick := int64(-1) // the runs do not overlap, so they can share the tick
+	scheduled := NewJob(every, func(ctx context.Context, _ int64, at time.Time) error {
+		if !isTriggeredRun(ctx) {
+			tick++
+		}
+		job(max(tick, 0), at, changeme)
+		return nil
+	})
+	scheduled.Clock = clock
+	scheduled.Immediate = true
+	scheduled.Overlap = OverlapPolicyQueue
+	if err := scheduled.Start(context.Background()); err != nil {
+		panic(err)
+	}
 
 	go func() {
-		tick := int64(0)
-		job(tick, time.Now(), changeme)
-		ticker := time.NewTicker(every)
+		defer scheduled.Stop()
 		for {
 			select {
 			case newtick := <-changeme:
-				if newtick > 0*time.Second {
-					ticker.Stop()
-					ticker = time.NewTicker(newtick)
-				}
+				scheduled.Reset(newtick)
 			case <-pingme:
-				job(tick, time.Now(), changeme)
-			case now := <-ticker.C:
-				tick++
-				job(tick, now, changeme)
+				scheduled.Trigger()
 			case <-stopme:
-				ticker.Stop()
 				return
 			}
 		}
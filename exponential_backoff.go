@@ -13,6 +13,18 @@ import (
 // A jitter factor is applied to the delay to avoid thundering herd problems. The jitter is a random value between
 // -jitter * delay and +jitter * delay.
 func ExponentialBackoff(attempt int, initialDelay time.Duration, maxDelay time.Duration, jitter float64) time.Duration {
+	return ExponentialBackoffWithRand(nil, attempt, initialDelay, maxDelay, jitter)
+}
+
+// ExponentialBackoffWithRand returns a duration to wait before retrying an operation, like ExponentialBackoff,
+// but the jitter is drawn from the given random source.
+//
+// Tests can give a seeded source to get reproducible delays. If random is nil, the global source is used.
+func ExponentialBackoffWithRand(random *rand.Rand, attempt int, initialDelay time.Duration, maxDelay time.Duration, jitter float64) time.Duration {
 	delay := min(time.Duration(math.Pow(2, float64(attempt-1)))*initialDelay, maxDelay)
-	return delay + time.Duration(rand.Float64()*float64(delay)*jitter*2) - time.Duration(float64(delay)*jitter)
+	draw := rand.Float64
+	if random != nil {
+		draw = random.Float64
+	}
+	return delay + time.Duration(draw()*float64(delay)*jitter*2) - time.Duration(float64(delay)*jitter)
 }
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Versioned describes types that can get their version
+//
+// The version is used as a weak ETag by RespondWithConditionalJSON and CheckPreconditions.
+type Versioned interface {
+	GetVersion() string
+}
+
+// Modifiable describes types that can get the time they were last modified
+//
+// The time is used as the Last-Modified header by RespondWithConditionalJSON and CheckPreconditions.
+type Modifiable interface {
+	GetLastModified() Time
+}
+
+// PreconditionFailedError is returned by CheckPreconditions when a precondition of a request is not met
+type PreconditionFailedError struct {
+	Header string
+	Value  string
+}
+
+// ETag returns the entity tag of a payload
+//
+// If the payload is Versioned, the tag is weak and made of the version (e.g.: W/"12"),
+// otherwise the tag is strong and made of a hash of the JSON payload.
+func ETag(payload any) (string, error) {
+	if versioned, ok := payload.(Versioned); ok {
+		return `W/"` + versioned.GetVersion() + `"`, nil
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return strongETag(body), nil
+}
+
+// RespondWithConditionalJSON will send a reply with a JSON payload and a HTTP Status code, unless the client already has it
+//
+// The ETag header is set (see ETag), and the Last-Modified header if the payload is Modifiable.
+//
+// For GET and HEAD requests with a successful code, a 304 Not Modified is sent instead of the payload
+// when If-None-Match matches the ETag, or, without If-None-Match, when the payload was not modified since If-Modified-Since.
+//
+// Like RespondWithJSON, a 500 Internal Server Error is sent if the payload cannot be marshaled.
+func RespondWithConditionalJSON(w http.ResponseWriter, r *http.Request, code int, payload any) {
+	var buffer bytes.Buffer
+	encoder := JSONEncoder{}
+	if err := encoder.Encode(&buffer, payload); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	body := buffer.Bytes()
+
+	etag := strongETag(body)
+	if versioned, ok := payload.(Versioned); ok {
+		etag = `W/"` + versioned.GetVersion() + `"`
+	}
+	w.Header().Set("ETag", etag)
+	lastModified, modifiable := lastModifiedOf(payload)
+	if modifiable {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && code >= 200 && code < 300 {
+		notModified := false
+		if ifNoneMatch := r.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+			notModified = matchETags(ifNoneMatch, etag)
+		} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && modifiable {
+			notModified = !lastModified.Truncate(time.Second).After(since)
+		}
+		if notModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	writeResponse(w, code, encoder.ContentType(), body)
+}
+
+// CheckPreconditions verifies the If-Match, If-Unmodified-Since, and If-None-Match headers of a request against the current state of a resource
+//
+// It is meant to be called before updating or deleting a resource, for optimistic concurrency.
+// current is nil if the resource does not exist (so If-None-Match: * can prevent overwriting a resource).
+//
+// If-Match accepts the ETag of the current resource (see ETag), the entity tags are compared weakly so clients can send versions.
+//
+// A PreconditionFailedError (412 Precondition Failed for ErrorStatus) is returned if a precondition is not met.
+//
+// Example:
+//
+//	order, err := store.Get(id)
+//	...
+//	if err := core.CheckPreconditions(r, order); err != nil {
+//		core.RespondWithInferredError(w, err)
+//		return
+//	}
+func CheckPreconditions(r *http.Request, current any) error {
+	if ifMatch := r.Header.Get("If-Match"); len(ifMatch) > 0 {
+		if current == nil {
+			return PreconditionFailedError{Header: "If-Match", Value: ifMatch}
+		}
+		etag, err := ETag(current)
+		if err != nil {
+			return err
+		}
+		if !matchETags(ifMatch, etag) {
+			return PreconditionFailedError{Header: "If-Match", Value: ifMatch}
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && current != nil {
+		if lastModified, ok := lastModifiedOf(current); ok && lastModified.Truncate(time.Second).After(since) {
+			return PreconditionFailedError{Header: "If-Unmodified-Since", Value: r.Header.Get("If-Unmodified-Since")}
+		}
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 && current != nil {
+		etag, err := ETag(current)
+		if err != nil {
+			return err
+		}
+		if matchETags(ifNoneMatch, etag) {
+			return PreconditionFailedError{Header: "If-None-Match", Value: ifNoneMatch}
+		}
+	}
+	return nil
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err PreconditionFailedError) Error() string {
+	return fmt.Sprintf(`Precondition %s: %s failed`, err.Header, err.Value)
+}
+
+// HTTPStatus returns 412 Precondition Failed
+//
+// implements HTTPStatuser
+func (err PreconditionFailedError) HTTPStatus() int {
+	return http.StatusPreconditionFailed
+}
+
+func strongETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(hash[:16]) + `"`
+}
+
+func lastModifiedOf(payload any) (time.Time, bool) {
+	if modifiable, ok := payload.(Modifiable); ok && !modifiable.GetLastModified().AsTime().IsZero() {
+		return modifiable.GetLastModified().AsTime(), true
+	}
+	return time.Time{}, false
+}
+
+// matchETags tells if a list of entity tags (from If-Match or If-None-Match) matches an entity tag, with the weak comparison
+func matchETags(list, etag string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package core_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Invoice struct {
+	Number    string    `json:"number"`
+	Version   string    `json:"version"`
+	UpdatedAt core.Time `json:"updatedAt"`
+}
+
+func (invoice Invoice) GetVersion() string {
+	return invoice.Version
+}
+
+func (invoice Invoice) GetLastModified() core.Time {
+	return invoice.UpdatedAt
+}
+
+func conditionalGet(headers map[string]string, payload any) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	recorder := httptest.NewRecorder()
+	core.RespondWithConditionalJSON(recorder, request, http.StatusOK, payload)
+	return recorder
+}
+
+func TestCanRespondWithConditionalJSON(t *testing.T) {
+	recorder := conditionalGet(nil, map[string]string{"hello": "world"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"hello": "world"}`, recorder.Body.String())
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.NotContains(t, etag, "W/")
+	assert.Empty(t, recorder.Header().Get("Last-Modified"))
+
+	recorder = conditionalGet(map[string]string{"If-None-Match": etag}, map[string]string{"hello": "world"})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+	assert.Equal(t, etag, recorder.Header().Get("ETag"))
+
+	recorder = conditionalGet(map[string]string{"If-None-Match": `"other", ` + etag}, map[string]string{"hello": "world"})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = conditionalGet(map[string]string{"If-None-Match": etag}, map[string]string{"hello": "everyone"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+}
+
+func TestCanRespondWithConditionalJSONFromVersionedPayload(t *testing.T) {
+	updatedAt := core.Time(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	invoice := Invoice{Number: "INV-1", Version: "12", UpdatedAt: updatedAt}
+
+	recorder := conditionalGet(nil, invoice)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `W/"12"`, recorder.Header().Get("ETag"))
+	assert.Equal(t, "Fri, 02 Jan 2026 03:04:05 GMT", recorder.Header().Get("Last-Modified"))
+
+	recorder = conditionalGet(map[string]string{"If-None-Match": `"12"`}, invoice)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = conditionalGet(map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"}, invoice)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	recorder = conditionalGet(map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:04 GMT"}, invoice)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// If-None-Match takes precedence over If-Modified-Since
+	recorder = conditionalGet(map[string]string{"If-None-Match": `W/"11"`, "If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"}, invoice)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestShouldNotRespondNotModifiedToUnsafeMethods(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.Header.Set("If-None-Match", "*")
+	recorder := httptest.NewRecorder()
+	core.RespondWithConditionalJSON(recorder, request, http.StatusCreated, map[string]string{"hello": "world"})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+func TestCanCheckPreconditions(t *testing.T) {
+	invoice := Invoice{Number: "INV-1", Version: "12", UpdatedAt: core.Time(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))}
+	tests := []struct {
+		name    string
+		headers map[string]string
+		current any
+		failed  string
+	}{
+		{"no precondition", nil, invoice, ""},
+		{"if-match", map[string]string{"If-Match": `W/"12"`}, invoice, ""},
+		{"if-match version", map[string]string{"If-Match": `"12"`}, invoice, ""},
+		{"if-match any", map[string]string{"If-Match": `*`}, invoice, ""},
+		{"if-match stale", map[string]string{"If-Match": `W/"11"`}, invoice, "If-Match"},
+		{"if-match missing", map[string]string{"If-Match": `*`}, nil, "If-Match"},
+		{"if-unmodified-since", map[string]string{"If-Unmodified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"}, invoice, ""},
+		{"if-unmodified-since stale", map[string]string{"If-Unmodified-Since": "Fri, 02 Jan 2026 03:00:00 GMT"}, invoice, "If-Unmodified-Since"},
+		{"if-none-match create", map[string]string{"If-None-Match": `*`}, nil, ""},
+		{"if-none-match exists", map[string]string{"If-None-Match": `*`}, invoice, "If-None-Match"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/invoices/INV-1", nil)
+			for key, value := range test.headers {
+				request.Header.Set(key, value)
+			}
+			err := core.CheckPreconditions(request, test.current)
+			if len(test.failed) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var preconditionError core.PreconditionFailedError
+			require.ErrorAs(t, err, &preconditionError)
+			assert.Equal(t, test.failed, preconditionError.Header)
+			assert.Equal(t, http.StatusPreconditionFailed, core.ErrorStatus(err))
+		})
+	}
+}
+
+func TestCanCheckPreconditionsWithStrongETag(t *testing.T) {
+	current := map[string]string{"hello": "world"}
+	etag, err := core.ETag(current)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodDelete, "/", nil)
+	request.Header.Set("If-Match", etag)
+	assert.NoError(t, core.CheckPreconditions(request, current))
+	assert.Error(t, core.CheckPreconditions(request, map[string]string{"hello": "everyone"}))
+}
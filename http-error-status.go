@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// HTTPStatuser describes errors that know their HTTP status code
+type HTTPStatuser interface {
+	HTTPStatus() int
+}
+
+// ErrorStatusRegistry maps errors to HTTP status codes
+//
+// Errors can be mapped by value (sentinel errors compared like errors.Is does) or by type.
+type ErrorStatusRegistry struct {
+	sentinels []sentinelStatus
+	types     []typeStatus
+	mutex     sync.RWMutex
+}
+
+type sentinelStatus struct {
+	target error
+	status int
+}
+
+type typeStatus struct {
+	match  func(err error) bool
+	status int
+}
+
+// ErrorStatuses is the ErrorStatusRegistry used by ErrorStatus, RespondWithInferredError and RespondWithInferredProblem
+//
+// It maps context.DeadlineExceeded to 504 and *http.MaxBytesError to 413.
+var ErrorStatuses = AddErrorType[*http.MaxBytesError](NewErrorStatusRegistry().Add(context.DeadlineExceeded, http.StatusGatewayTimeout), http.StatusRequestEntityTooLarge)
+
+// NewErrorStatusRegistry returns a new empty ErrorStatusRegistry
+func NewErrorStatusRegistry() *ErrorStatusRegistry {
+	return &ErrorStatusRegistry{}
+}
+
+// Add maps a sentinel error to an HTTP status code
+//
+// Example:
+//
+//	core.ErrorStatuses.Add(sql.ErrNoRows, http.StatusNotFound)
+func (registry *ErrorStatusRegistry) Add(target error, status int) *ErrorStatusRegistry {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.sentinels = append(registry.sentinels, sentinelStatus{target: target, status: status})
+	return registry
+}
+
+// AddErrorType maps an error type to an HTTP status code
+//
+// Example:
+//
+//	core.AddErrorType[*json.SyntaxError](core.ErrorStatuses, http.StatusBadRequest)
+func AddErrorType[E error](registry *ErrorStatusRegistry, status int) *ErrorStatusRegistry {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.types = append(registry.types, typeStatus{
+		match:  func(err error) bool { _, ok := err.(E); return ok },
+		status: status,
+	})
+	return registry
+}
+
+// StatusOf returns the HTTP status code of an error
+//
+// The error and the errors it wraps (with fmt.Errorf or errors.Join) are walked depth first, the first one that
+// implements HTTPStatuser, carries a ProblemDetails status, or is registered, gives the status code.
+//
+// If no error matches, http.StatusInternalServerError is returned.
+func (registry *ErrorStatusRegistry) StatusOf(err error) int {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	status := http.StatusInternalServerError
+	walkErrors(err, func(err error) bool {
+		if statuser, ok := err.(HTTPStatuser); ok && statuser.HTTPStatus() > 0 {
+			status = statuser.HTTPStatus()
+			return false
+		}
+		if detailer, ok := err.(ProblemDetailer); ok && detailer.GetProblemDetails().Status > 0 {
+			status = detailer.GetProblemDetails().Status
+			return false
+		}
+		for _, sentinel := range registry.sentinels {
+			if isSameError(err, sentinel.target) {
+				status = sentinel.status
+				return false
+			}
+		}
+		for _, errorType := range registry.types {
+			if errorType.match(err) {
+				status = errorType.status
+				return false
+			}
+		}
+		return true
+	})
+	return status
+}
+
+// ErrorStatus returns the HTTP status code of an error using the ErrorStatuses registry
+func ErrorStatus(err error) int {
+	return ErrorStatuses.StatusOf(err)
+}
+
+// RespondWithInferredError will send a reply with an error as JSON, the HTTP Status code is given by ErrorStatus
+func RespondWithInferredError(w http.ResponseWriter, err error) {
+	RespondWithError(w, ErrorStatus(err), err)
+}
+
+// RespondWithInferredProblem will send a reply with an error as RFC 9457 problem details, the HTTP Status code is given by ErrorStatus
+func RespondWithInferredProblem(w http.ResponseWriter, err error) {
+	RespondWithProblem(w, ErrorStatus(err), err)
+}
+
+// isSameError tells if err is target, without unwrapping err (like one step of errors.Is)
+func isSameError(err, target error) bool {
+	if reflect.TypeOf(target).Comparable() && err == target {
+		return true
+	}
+	if matcher, ok := err.(interface{ Is(error) bool }); ok {
+		return matcher.Is(target)
+	}
+	return false
+}
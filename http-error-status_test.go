@@ -0,0 +1,89 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type TeapotError struct{}
+
+func (err TeapotError) Error() string {
+	return "I am a teapot"
+}
+
+func (err TeapotError) HTTPStatus() int {
+	return http.StatusTeapot
+}
+
+type ValidationError struct {
+	Field string
+}
+
+func (err *ValidationError) Error() string {
+	return "invalid " + err.Field
+}
+
+func TestCanGetErrorStatus(t *testing.T) {
+	registry := core.AddErrorType[*ValidationError](core.NewErrorStatusRegistry().Add(ErrNotFound, http.StatusNotFound), http.StatusBadRequest)
+
+	assert.Equal(t, http.StatusNotFound, registry.StatusOf(ErrNotFound))
+	assert.Equal(t, http.StatusNotFound, registry.StatusOf(fmt.Errorf("user 1234: %w", ErrNotFound)))
+	assert.Equal(t, http.StatusBadRequest, registry.StatusOf(&ValidationError{Field: "name"}))
+	assert.Equal(t, http.StatusBadRequest, registry.StatusOf(fmt.Errorf("cannot create user: %w", &ValidationError{Field: "name"})))
+	assert.Equal(t, http.StatusTeapot, registry.StatusOf(fmt.Errorf("cannot brew: %w", TeapotError{})))
+	assert.Equal(t, http.StatusForbidden, registry.StatusOf(core.ProblemDetails{Status: http.StatusForbidden}))
+	assert.Equal(t, http.StatusInternalServerError, registry.StatusOf(errors.New("something else")))
+	assert.Equal(t, http.StatusInternalServerError, registry.StatusOf(nil))
+
+	// the first error of a join wins
+	assert.Equal(t, http.StatusBadRequest, registry.StatusOf(errors.Join(errors.New("oops"), &ValidationError{Field: "name"}, ErrNotFound)))
+	assert.Equal(t, http.StatusNotFound, registry.StatusOf(errors.Join(ErrNotFound, &ValidationError{Field: "name"})))
+	// the outermost error wins
+	assert.Equal(t, http.StatusTeapot, registry.StatusOf(fmt.Errorf("%w: %w", TeapotError{}, ErrNotFound)))
+}
+
+func TestCanGetDefaultErrorStatus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, http.StatusGatewayTimeout, core.ErrorStatus(ctx.Err()))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, core.ErrorStatus(fmt.Errorf("cannot read body: %w", &http.MaxBytesError{Limit: 10})))
+}
+
+func TestHTTPResponderWithInferredError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		core.RespondWithInferredError(res, fmt.Errorf("cannot brew: %w", TeapotError{}))
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.NoErrorf(t, err, "Failed to get %s", server.URL)
+	assert.Equal(t, http.StatusTeapot, res.StatusCode)
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(res.Body)
+	require.NoErrorf(t, err, "Failed to read body of %s", server.URL)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(body, &payload), "Failed to unmarshal JSON")
+	assert.Equal(t, map[string]string{"error": "cannot brew: I am a teapot", "http_status": "418"}, payload)
+}
+
+func TestHTTPResponderWithInferredProblem(t *testing.T) {
+	res, payload := getProblem(t, func(res http.ResponseWriter, req *http.Request) {
+		core.RespondWithInferredProblem(res, errors.New("boom"))
+	})
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	assert.Equal(t, float64(500), payload["status"])
+	assert.Equal(t, "boom", payload["detail"])
+}
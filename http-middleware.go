@@ -0,0 +1,247 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader is the header used by RequestIDMiddleware
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	serverTimingContextKey
+)
+
+// middlewareWriter is the http.ResponseWriter given to handlers by the middlewares of this package
+//
+// It records the status code and the size of the response, and can carry a request ID.
+type middlewareWriter struct {
+	http.ResponseWriter
+	requestID   string
+	status      int
+	size        int64
+	beforeWrite func(w *middlewareWriter)
+}
+
+// serverTimings collects the Server-Timing metrics of a request
+type serverTimings struct {
+	metrics []string
+	mutex   sync.Mutex
+}
+
+// RequestIDMiddleware gives an ID to each request
+//
+// The ID comes from the X-Request-ID header of the request if it is valid, otherwise a new UUID is generated.
+// The ID is sent back in the X-Request-ID header of the response, it is stored in the request context
+// (see RequestIDFromContext), and RespondWithError and RespondWithProblem add it to the errors they send.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = NewUUID().String()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(
+			&middlewareWriter{ResponseWriter: w, requestID: requestID},
+			r.WithContext(context.WithValue(r.Context(), requestIDContextKey, requestID)),
+		)
+	})
+}
+
+// RequestIDFromContext returns the request ID stored in a context by RequestIDMiddleware
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDContextKey).(string)
+	return requestID, ok
+}
+
+// RecoveryMiddleware turns the panics of handlers into 500 Internal Server Error problem details
+//
+// Panics with an error (like the ones from Must) are sent with the error message.
+// The panics are logged with their stack trace in the default slog.Logger.
+//
+// If the handler already started its response, the response cannot be changed and the connection is aborted.
+// http.ErrAbortHandler is not recovered.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := &middlewareWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("panic: %v", recovered)
+			}
+			if errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+			slog.ErrorContext(r.Context(), "Recovered from a panic", "error", err, "method", r.Method, "path", r.URL.Path, "stack", string(debug.Stack()))
+			if writer.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+			RespondWithProblem(writer, http.StatusInternalServerError, err)
+		}()
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// ServerTimingMiddleware sends the Server-Timing header with the time spent by the handler
+//
+// Handlers can add their own metrics with AddServerTiming. The "total" metric is the time spent
+// until the handler started its response.
+func ServerTimingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		timings := &serverTimings{}
+		writer := &middlewareWriter{ResponseWriter: w, beforeWrite: func(writer *middlewareWriter) {
+			timings.mutex.Lock()
+			defer timings.mutex.Unlock()
+			metrics := append(append([]string{}, timings.metrics...), formatServerTiming("total", time.Since(start)))
+			writer.Header().Set("Server-Timing", strings.Join(metrics, ", "))
+		}}
+		next.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), serverTimingContextKey, timings)))
+	})
+}
+
+// AddServerTiming adds a metric to the Server-Timing header of the response of a request
+//
+// The request must go through ServerTimingMiddleware and the metric must be added before the response is started.
+//
+// Example:
+//
+//	start := time.Now()
+//	users, err := store.FindUsers(r.Context())
+//	core.AddServerTiming(r.Context(), "db", time.Since(start))
+func AddServerTiming(ctx context.Context, name string, duration time.Duration) {
+	if timings, ok := ctx.Value(serverTimingContextKey).(*serverTimings); ok {
+		timings.mutex.Lock()
+		defer timings.mutex.Unlock()
+		timings.metrics = append(timings.metrics, formatServerTiming(name, duration))
+	}
+}
+
+// AccessLogMiddleware logs each request with the given slog.Logger once it is served
+//
+// The log contains the method, path, status code, size and duration of the request, and its ID if it went through RequestIDMiddleware.
+// Requests that end with a 5xx status code are logged as errors.
+//
+// If logger is nil, the default slog.Logger is used.
+func AccessLogMiddleware(logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			writer := &middlewareWriter{ResponseWriter: w}
+			defer func() {
+				log := logger
+				if log == nil {
+					log = slog.Default()
+				}
+				status := writer.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				level := slog.LevelInfo
+				if status >= 500 {
+					level = slog.LevelError
+				}
+				attributes := []slog.Attr{
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", status),
+					slog.Int64("size", writer.size),
+					slog.Duration("duration", time.Since(start)),
+					slog.String("remote", r.RemoteAddr),
+					slog.String("user_agent", r.UserAgent()),
+				}
+				if requestID, ok := RequestIDFromContext(r.Context()); ok {
+					attributes = append(attributes, slog.String("request_id", requestID))
+				}
+				log.LogAttrs(r.Context(), level, "Request served", attributes...)
+			}()
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
+
+// WriteHeader sends the response header with the given status code
+//
+// implements http.ResponseWriter
+func (writer *middlewareWriter) WriteHeader(code int) {
+	if writer.status == 0 {
+		if writer.beforeWrite != nil {
+			writer.beforeWrite(writer)
+		}
+		writer.status = code
+	}
+	writer.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the data of the response
+//
+// implements http.ResponseWriter
+func (writer *middlewareWriter) Write(data []byte) (int, error) {
+	if writer.status == 0 {
+		writer.WriteHeader(http.StatusOK)
+	}
+	size, err := writer.ResponseWriter.Write(data)
+	writer.size += int64(size)
+	return size, err
+}
+
+// Flush sends the buffered data to the client
+//
+// implements http.Flusher
+func (writer *middlewareWriter) Flush() {
+	if writer.status == 0 {
+		writer.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController
+func (writer *middlewareWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// requestIDFromWriter finds the request ID carried by a http.ResponseWriter from RequestIDMiddleware
+func requestIDFromWriter(w http.ResponseWriter) (string, bool) {
+	for {
+		if writer, ok := w.(*middlewareWriter); ok && len(writer.requestID) > 0 {
+			return writer.requestID, true
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return "", false
+		}
+		w = unwrapper.Unwrap()
+	}
+}
+
+// isValidRequestID tells if a request ID sent by a client can be used
+func isValidRequestID(requestID string) bool {
+	if len(requestID) == 0 || len(requestID) > 128 {
+		return false
+	}
+	for _, char := range requestID {
+		if char < '!' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func formatServerTiming(name string, duration time.Duration) string {
+	return name + ";dur=" + strconv.FormatFloat(float64(duration.Microseconds())/1000, 'f', -1, 64)
+}
@@ -0,0 +1,135 @@
+package core_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanGenerateRequestID(t *testing.T) {
+	var requestID string
+	handler := core.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var found bool
+		requestID, found = core.RequestIDFromContext(r.Context())
+		assert.True(t, found, "The request ID should be in the context")
+		core.RespondWithError(w, http.StatusNotFound, errors.New("Not found"))
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	_, err := uuid.Parse(requestID)
+	require.NoError(t, err, "The request ID should be a UUID")
+	assert.Equal(t, requestID, recorder.Header().Get(core.RequestIDHeader))
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload))
+	assert.Equal(t, requestID, payload["request_id"])
+}
+
+func TestCanAcceptRequestID(t *testing.T) {
+	handler := core.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		core.RespondWithProblem(w, http.StatusConflict, errors.New("Conflict"))
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(core.RequestIDHeader, "abc-123")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, "abc-123", recorder.Header().Get(core.RequestIDHeader))
+	var problem core.ProblemDetails
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "abc-123", problem.Extensions["request_id"])
+
+	request.Header.Set(core.RequestIDHeader, "not valid\n")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.NotEqual(t, "not valid\n", recorder.Header().Get(core.RequestIDHeader))
+}
+
+func TestCanRecoverFromPanics(t *testing.T) {
+	handler := core.RequestIDMiddleware(core.RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = core.Must(0, errors.New("Something went wrong"))
+	})))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, core.ProblemDetailsContentType, recorder.Header().Get("Content-Type"))
+	var problem core.ProblemDetails
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "Something went wrong", problem.Detail)
+	assert.Equal(t, recorder.Header().Get(core.RequestIDHeader), problem.Extensions["request_id"])
+}
+
+func TestCanRecoverFromPanicsWithValues(t *testing.T) {
+	handler := core.RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	var problem core.ProblemDetails
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "panic: oops", problem.Detail)
+}
+
+func TestShouldAbortWhenPanickingAfterResponding(t *testing.T) {
+	handler := core.RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("oops")
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestCanSendServerTiming(t *testing.T) {
+	handler := core.ServerTimingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		core.AddServerTiming(r.Context(), "db", 1500*time.Microsecond)
+		core.RespondWithJSON(w, http.StatusOK, "hello")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Regexp(t, regexp.MustCompile(`^db;dur=1\.5, total;dur=[0-9.]+$`), recorder.Header().Get("Server-Timing"))
+}
+
+func TestCanLogAccess(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, nil))
+	handler := core.RequestIDMiddleware(core.AccessLogMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		core.RespondWithJSON(w, http.StatusCreated, "hello")
+	})))
+	request := httptest.NewRequest(http.MethodPost, "/users", nil)
+	request.Header.Set(core.RequestIDHeader, "abc-123")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(output.Bytes(), &entry))
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "Request served", entry["msg"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, "/users", entry["path"])
+	assert.Equal(t, float64(http.StatusCreated), entry["status"])
+	assert.Equal(t, float64(len(`"hello"`)), entry["size"])
+	assert.Equal(t, "abc-123", entry["request_id"])
+}
+
+func TestCanStreamThroughMiddlewares(t *testing.T) {
+	handler := core.RequestIDMiddleware(core.ServerTimingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+		assert.True(t, ok, "The writer should be a http.Flusher")
+		require.NoError(t, http.NewResponseController(w).Flush())
+	})))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, recorder.Flushed)
+	assert.NotEmpty(t, recorder.Header().Get("Server-Timing"))
+}
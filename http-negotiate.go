@@ -0,0 +1,458 @@
+package core
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ResponseEncoder encodes payloads for a media type
+type ResponseEncoder interface {
+	// ContentType returns the media type of the encoded payloads (e.g. "application/json; charset=utf-8")
+	ContentType() string
+
+	// Encode writes the encoded payload
+	Encode(w io.Writer, payload any) error
+}
+
+// ContentNegotiator chooses a ResponseEncoder according to the Accept header of a request
+//
+// The order of the encoders matters: the first encoder is used when the request does not have an Accept header,
+// and when the client accepts more than one media type with the same quality.
+type ContentNegotiator []ResponseEncoder
+
+// JSONEncoder encodes payloads as JSON, like RespondWithJSON
+//
+// When Indent is not empty, the JSON is pretty-printed.
+// When Callback is not empty, the JSON is wrapped in a JSONP call to that function.
+type JSONEncoder struct {
+	Indent   string
+	Callback string
+}
+
+// InvalidJSONPCallbackError is returned when a JSONP callback is not a valid JavaScript identifier
+type InvalidJSONPCallbackError struct {
+	Callback string
+}
+
+// XMLEncoder encodes payloads as XML
+type XMLEncoder struct{}
+
+// YAMLEncoder encodes payloads as YAML
+type YAMLEncoder struct{}
+
+// CSVEncoder encodes payloads as CSV
+//
+// The payload can be a [][]string, a struct, a slice of structs or a slice of maps.
+// The header of the CSV is made of the JSON names of the struct fields, or of the sorted map keys.
+type CSVEncoder struct{}
+
+// HTMLTemplateEncoder encodes payloads as HTML with a template, like RespondWithHTMLTemplate
+type HTMLTemplateEncoder struct {
+	Template *template.Template
+	Name     string
+}
+
+// DefaultContentNegotiator is the ContentNegotiator used by Respond
+//
+// It encodes JSON (the default), XML, YAML and CSV. Add an HTMLTemplateEncoder to serve browsers:
+//
+//	negotiator := append(core.ContentNegotiator{core.HTMLTemplateEncoder{Template: page, Name: "page"}}, core.DefaultContentNegotiator...)
+var DefaultContentNegotiator = ContentNegotiator{JSONEncoder{}, XMLEncoder{}, YAMLEncoder{}, CSVEncoder{}}
+
+// Respond will send a reply with a payload encoded according to the Accept header of the request and a HTTP Status code
+//
+// See ContentNegotiator.Respond
+func Respond(w http.ResponseWriter, r *http.Request, code int, payload any) {
+	DefaultContentNegotiator.Respond(w, r, code, payload)
+}
+
+// Respond will send a reply with a payload encoded according to the Accept header of the request and a HTTP Status code
+//
+// The payload is encoded with the best encoder accepted by the client, if it cannot encode the payload,
+// the next acceptable encoder is tried. If no encoder is acceptable, a 406 Not Acceptable error is sent,
+// if no acceptable encoder can encode the payload, a 500 Internal Server Error is sent.
+func (negotiator ContentNegotiator) Respond(w http.ResponseWriter, r *http.Request, code int, payload any) {
+	w.Header().Add("Vary", "Accept")
+	encoders := negotiator.Negotiate(r.Header.Get("Accept"))
+	if len(encoders) == 0 {
+		RespondWithError(w, http.StatusNotAcceptable, fmt.Errorf(`None of the accepted media types "%s" is supported, supported: %s`, r.Header.Get("Accept"), strings.Join(negotiator.contentTypes(), ", ")))
+		return
+	}
+	var err error
+	for _, encoder := range encoders {
+		var buffer bytes.Buffer
+		if err = encoder.Encode(&buffer, payload); err == nil {
+			writeResponse(w, code, encoder.ContentType(), buffer.Bytes())
+			return
+		}
+	}
+	RespondWithError(w, http.StatusInternalServerError, err)
+}
+
+// Negotiate returns the encoders that are acceptable according to an Accept header, the best first
+//
+// The encoders are sorted by the quality of their media range, then by its specificity (application/xml before */*),
+// then by their order in the ContentNegotiator. Media ranges with a quality of 0 are excluded.
+// An empty Accept header accepts all encoders.
+func (negotiator ContentNegotiator) Negotiate(accept string) []ResponseEncoder {
+	if len(strings.TrimSpace(accept)) == 0 {
+		accept = "*/*"
+	}
+	ranges := parseAccept(accept)
+	encoders := make([]ResponseEncoder, 0, len(negotiator))
+	for _, encoder := range negotiator {
+		mediaType, _, err := mime.ParseMediaType(encoder.ContentType())
+		if err != nil {
+			continue
+		}
+		// the quality of an encoder is given by the most specific range that matches it
+		best := -1
+		for index, mediaRange := range ranges {
+			if mediaRange.matches(mediaType) && (best < 0 || mediaRange.specificity() > ranges[best].specificity()) {
+				best = index
+			}
+		}
+		if best >= 0 && ranges[best].quality > 0 {
+			encoders = append(encoders, weightedEncoder{encoder, ranges[best].quality, ranges[best].specificity()})
+		}
+	}
+	sort.SliceStable(encoders, func(i, j int) bool {
+		a, b := encoders[i].(weightedEncoder), encoders[j].(weightedEncoder)
+		if a.quality != b.quality {
+			return a.quality > b.quality
+		}
+		return a.specificity > b.specificity
+	})
+	for i, encoder := range encoders {
+		encoders[i] = encoder.(weightedEncoder).ResponseEncoder
+	}
+	return encoders
+}
+
+func (negotiator ContentNegotiator) contentTypes() []string {
+	contentTypes := make([]string, 0, len(negotiator))
+	for _, encoder := range negotiator {
+		if mediaType, _, err := mime.ParseMediaType(encoder.ContentType()); err == nil {
+			contentTypes = append(contentTypes, mediaType)
+		}
+	}
+	return contentTypes
+}
+
+type weightedEncoder struct {
+	ResponseEncoder
+	quality     float64
+	specificity int
+}
+
+type mediaRange struct {
+	mainType string
+	subType  string
+	quality  float64
+}
+
+func parseAccept(accept string) (ranges []mediaRange) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		mainType, subType, found := strings.Cut(mediaType, "/")
+		if !found {
+			continue
+		}
+		quality := 1.0
+		if value, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(value, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mainType: mainType, subType: subType, quality: quality})
+	}
+	return ranges
+}
+
+func (mediaRange mediaRange) matches(mediaType string) bool {
+	mainType, subType, _ := strings.Cut(mediaType, "/")
+	return (mediaRange.mainType == "*" || mediaRange.mainType == mainType) && (mediaRange.subType == "*" || mediaRange.subType == subType)
+}
+
+func (mediaRange mediaRange) specificity() int {
+	switch {
+	case mediaRange.mainType == "*":
+		return 0
+	case mediaRange.subType == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// JSONEncoderFromRequest returns a JSONEncoder configured by the query of the request
+//
+// The "pretty" query parameter pretty-prints the JSON (?pretty, ?pretty=true),
+// the "callback" query parameter wraps it in a JSONP call (?callback=handle).
+//
+// An InvalidJSONPCallbackError is returned if the callback is not a valid JavaScript identifier.
+func JSONEncoderFromRequest(r *http.Request) (JSONEncoder, error) {
+	encoder := JSONEncoder{}
+	query := r.URL.Query()
+	if query.Has("pretty") {
+		if pretty, err := parseBool(query.Get("pretty")); len(query.Get("pretty")) == 0 || (err == nil && pretty) {
+			encoder.Indent = "  "
+		}
+	}
+	if callback := query.Get("callback"); len(callback) > 0 {
+		if !jsonpCallbackPattern.MatchString(callback) {
+			return JSONEncoder{}, InvalidJSONPCallbackError{Callback: callback}
+		}
+		encoder.Callback = callback
+	}
+	return encoder, nil
+}
+
+var jsonpCallbackPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// ContentType returns "application/json; charset=utf-8", or "application/javascript; charset=utf-8" for JSONP
+//
+// implements ResponseEncoder
+func (encoder JSONEncoder) ContentType() string {
+	if len(encoder.Callback) > 0 {
+		return "application/javascript; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Encode writes the payload as JSON
+//
+// implements ResponseEncoder
+func (encoder JSONEncoder) Encode(w io.Writer, payload any) (err error) {
+	var response []byte
+	if len(encoder.Indent) > 0 {
+		response, err = json.MarshalIndent(payload, "", encoder.Indent)
+	} else {
+		response, err = json.Marshal(payload)
+	}
+	if err != nil {
+		return err
+	}
+	if len(encoder.Callback) > 0 {
+		if !jsonpCallbackPattern.MatchString(encoder.Callback) {
+			return InvalidJSONPCallbackError{Callback: encoder.Callback}
+		}
+		// the comment prevents the Rosetta Flash attack
+		response = []byte("/**/" + encoder.Callback + "(" + string(response) + ");")
+	}
+	_, err = w.Write(response)
+	return err
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err InvalidJSONPCallbackError) Error() string {
+	return fmt.Sprintf(`"%s" is not a valid JSONP callback`, err.Callback)
+}
+
+// HTTPStatus returns 400 Bad Request
+//
+// implements HTTPStatuser
+func (err InvalidJSONPCallbackError) HTTPStatus() int {
+	return http.StatusBadRequest
+}
+
+// ContentType returns "application/xml; charset=utf-8"
+//
+// implements ResponseEncoder
+func (encoder XMLEncoder) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+// Encode writes the payload as XML
+//
+// implements ResponseEncoder
+func (encoder XMLEncoder) Encode(w io.Writer, payload any) error {
+	response, err := xml.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, xml.Header)
+	if err == nil {
+		_, err = w.Write(response)
+	}
+	return err
+}
+
+// ContentType returns "application/yaml; charset=utf-8"
+//
+// implements ResponseEncoder
+func (encoder YAMLEncoder) ContentType() string {
+	return "application/yaml; charset=utf-8"
+}
+
+// Encode writes the payload as YAML
+//
+// implements ResponseEncoder
+func (encoder YAMLEncoder) Encode(w io.Writer, payload any) error {
+	yamlEncoder := yaml.NewEncoder(w)
+	if err := yamlEncoder.Encode(payload); err != nil {
+		return err
+	}
+	return yamlEncoder.Close()
+}
+
+// ContentType returns "text/csv; charset=utf-8"
+//
+// implements ResponseEncoder
+func (encoder CSVEncoder) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+// Encode writes the payload as CSV
+//
+// implements ResponseEncoder
+func (encoder CSVEncoder) Encode(w io.Writer, payload any) error {
+	records, err := csvRecords(payload)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	return writer.WriteAll(records)
+}
+
+// ContentType returns "text/html; charset=utf-8"
+//
+// implements ResponseEncoder
+func (encoder HTMLTemplateEncoder) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+// Encode executes the template with the payload
+//
+// implements ResponseEncoder
+func (encoder HTMLTemplateEncoder) Encode(w io.Writer, payload any) error {
+	return encoder.Template.ExecuteTemplate(w, encoder.Name, payload)
+}
+
+func csvRecords(payload any) ([][]string, error) {
+	if records, ok := payload.([][]string); ok {
+		return records, nil
+	}
+	value := reflect.ValueOf(payload)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		header, indexes := csvHeader(value.Type())
+		return [][]string{header, csvRow(value, indexes)}, nil
+	case reflect.Slice, reflect.Array:
+		itemType := value.Type().Elem()
+		for itemType.Kind() == reflect.Pointer {
+			itemType = itemType.Elem()
+		}
+		switch {
+		case itemType.Kind() == reflect.Struct:
+			header, indexes := csvHeader(itemType)
+			records := [][]string{header}
+			for i := 0; i < value.Len(); i++ {
+				records = append(records, csvRow(value.Index(i), indexes))
+			}
+			return records, nil
+		case itemType.Kind() == reflect.Map && itemType.Key().Kind() == reflect.String:
+			keys := map[string]bool{}
+			for i := 0; i < value.Len(); i++ {
+				for _, key := range reflect.Indirect(value.Index(i)).MapKeys() {
+					keys[key.String()] = true
+				}
+			}
+			header := make([]string, 0, len(keys))
+			for key := range keys {
+				header = append(header, key)
+			}
+			sort.Strings(header)
+			records := [][]string{header}
+			for i := 0; i < value.Len(); i++ {
+				item := reflect.Indirect(value.Index(i))
+				record := make([]string, len(header))
+				for column, key := range header {
+					if !item.IsValid() {
+						continue
+					}
+					record[column] = csvField(item.MapIndex(reflect.ValueOf(key).Convert(itemType.Key())))
+				}
+				records = append(records, record)
+			}
+			return records, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot encode %T as CSV", payload)
+}
+
+func csvHeader(structType reflect.Type) (header []string, indexes [][]int) {
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		if tag, found := field.Tag.Lookup("json"); found {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if len(tagName) > 0 {
+				name = tagName
+			}
+		}
+		header = append(header, name)
+		indexes = append(indexes, field.Index)
+	}
+	return header, indexes
+}
+
+func csvRow(value reflect.Value, indexes [][]int) []string {
+	value = reflect.Indirect(value)
+	record := make([]string, len(indexes))
+	if !value.IsValid() {
+		return record
+	}
+	for column, index := range indexes {
+		if field, err := value.FieldByIndexErr(index); err == nil {
+			record[column] = csvField(field)
+		}
+	}
+	return record
+}
+
+func csvField(value reflect.Value) string {
+	if !value.IsValid() {
+		return ""
+	}
+	if (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) && value.IsNil() {
+		return ""
+	}
+	switch actual := value.Interface().(type) {
+	case encoding.TextMarshaler:
+		if text, err := actual.MarshalText(); err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return actual.String()
+	}
+	return fmt.Sprint(reflect.Indirect(value).Interface())
+}
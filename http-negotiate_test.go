@@ -0,0 +1,128 @@
+package core_test
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type NegotiatedItem struct {
+	ID     int    `json:"id" xml:"id" yaml:"id"`
+	Name   string `json:"name" xml:"name" yaml:"name"`
+	Secret string `json:"-" xml:"-" yaml:"-"`
+}
+
+func negotiate(t *testing.T, negotiator core.ContentNegotiator, accept string, payload any) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if len(accept) > 0 {
+		request.Header.Set("Accept", accept)
+	}
+	recorder := httptest.NewRecorder()
+	negotiator.Respond(recorder, request, http.StatusOK, payload)
+	return recorder
+}
+
+func TestCanNegotiateEncoders(t *testing.T) {
+	negotiator := core.DefaultContentNegotiator
+	tests := []struct {
+		accept   string
+		expected []string
+	}{
+		{"", []string{"application/json; charset=utf-8", "application/xml; charset=utf-8", "application/yaml; charset=utf-8", "text/csv; charset=utf-8"}},
+		{"text/csv", []string{"text/csv; charset=utf-8"}},
+		{"application/xml;q=0.9, application/json", []string{"application/json; charset=utf-8", "application/xml; charset=utf-8"}},
+		{"application/*;q=0.5, application/yaml", []string{"application/yaml; charset=utf-8", "application/json; charset=utf-8", "application/xml; charset=utf-8"}},
+		{"*/*;q=0.1, application/json;q=0", []string{"application/xml; charset=utf-8", "application/yaml; charset=utf-8", "text/csv; charset=utf-8"}},
+		{"application/xml, */*", []string{"application/xml; charset=utf-8", "application/json; charset=utf-8", "application/yaml; charset=utf-8", "text/csv; charset=utf-8"}},
+		{"text/*, application/yaml, */*", []string{"application/yaml; charset=utf-8", "text/csv; charset=utf-8", "application/json; charset=utf-8", "application/xml; charset=utf-8"}},
+		{"image/png", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			encoders := negotiator.Negotiate(test.accept)
+			contentTypes := make([]string, 0, len(encoders))
+			for _, encoder := range encoders {
+				contentTypes = append(contentTypes, encoder.ContentType())
+			}
+			assert.Equal(t, test.expected, contentTypes)
+		})
+	}
+}
+
+func TestCanRespondWithJSONByDefault(t *testing.T) {
+	recorder := negotiate(t, core.DefaultContentNegotiator, "", NegotiatedItem{ID: 1, Name: "one"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", recorder.Header().Get("Vary"))
+	assert.JSONEq(t, `{"id": 1, "name": "one"}`, recorder.Body.String())
+}
+
+func TestCanRespondWithXML(t *testing.T) {
+	recorder := negotiate(t, core.DefaultContentNegotiator, "application/xml", NegotiatedItem{ID: 1, Name: "one"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "<NegotiatedItem><id>1</id><name>one</name></NegotiatedItem>")
+}
+
+func TestCanRespondWithYAML(t *testing.T) {
+	recorder := negotiate(t, core.DefaultContentNegotiator, "application/yaml", NegotiatedItem{ID: 1, Name: "one"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/yaml; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 1\nname: one\n", recorder.Body.String())
+}
+
+func TestCanRespondWithCSV(t *testing.T) {
+	items := []*NegotiatedItem{{ID: 1, Name: "one", Secret: "s1"}, {ID: 2, Name: "two, three"}}
+	recorder := negotiate(t, core.DefaultContentNegotiator, "text/csv", items)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name\n1,one\n2,\"two, three\"\n", recorder.Body.String())
+}
+
+func TestCanRespondWithCSVFromMaps(t *testing.T) {
+	items := []map[string]any{{"name": "one", "id": 1}, {"id": 2, "extra": true}}
+	recorder := negotiate(t, core.DefaultContentNegotiator, "text/csv", items)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "extra,id,name\n,1,one\ntrue,2,\n", recorder.Body.String())
+}
+
+func TestCanRespondWithHTMLTemplate(t *testing.T) {
+	page := template.Must(template.New("item").Parse(`<p>{{ .Name }}</p>`))
+	negotiator := append(core.ContentNegotiator{core.HTMLTemplateEncoder{Template: page, Name: "item"}}, core.DefaultContentNegotiator...)
+
+	recorder := negotiate(t, negotiator, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", NegotiatedItem{ID: 1, Name: "<one>"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "<p>&lt;one&gt;</p>", recorder.Body.String())
+
+	recorder = negotiate(t, negotiator, "application/json", NegotiatedItem{ID: 1, Name: "one"})
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+}
+
+func TestCanRespondWithNextEncoderWhenEncodingFails(t *testing.T) {
+	// encoding/xml does not support maps
+	recorder := negotiate(t, core.DefaultContentNegotiator, "application/xml, application/json;q=0.5", map[string]string{"hello": "world"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"hello": "world"}`, recorder.Body.String())
+}
+
+func TestShouldFailRespondingWhenNoEncoderIsAcceptable(t *testing.T) {
+	recorder := negotiate(t, core.DefaultContentNegotiator, "image/png", NegotiatedItem{ID: 1, Name: "one"})
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload))
+	assert.Contains(t, payload["error"], "image/png")
+}
+
+func TestShouldFailRespondingWhenNoEncoderCanEncode(t *testing.T) {
+	recorder := negotiate(t, core.DefaultContentNegotiator, "text/csv", "just a string")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemDetails is a problem details object as described by RFC 9457
+//
+// The Extensions are marshaled as members of the object, next to the standard members.
+type ProblemDetails struct {
+	Type       string         `json:"type,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Status     int            `json:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// ProblemDetailer describes errors that supply their own ProblemDetails
+//
+// Empty members are filled by NewProblemDetails.
+type ProblemDetailer interface {
+	GetProblemDetails() ProblemDetails
+}
+
+// ProblemDetailsContentType is the media type of ProblemDetails in JSON
+const ProblemDetailsContentType = "application/problem+json"
+
+// NewProblemDetails builds the ProblemDetails of an error
+//
+// If the error, or one of the errors it wraps, is a ProblemDetailer, its ProblemDetails are used.
+// The Title defaults to the HTTP status text and the Detail to the error message.
+// The ID, What, and Value fields of the errors are added as extensions, like RespondWithError does.
+func NewProblemDetails(code int, err error) ProblemDetails {
+	var problem ProblemDetails
+	var detailer ProblemDetailer
+	if errors.As(err, &detailer) {
+		problem = detailer.GetProblemDetails()
+	}
+	problem.Status = code
+	if len(problem.Title) == 0 {
+		problem.Title = http.StatusText(code)
+	}
+	if len(problem.Detail) == 0 && err != nil {
+		problem.Detail = err.Error()
+	}
+	for key, value := range errorProperties(err) {
+		if _, found := problem.Extensions[key]; !found {
+			if problem.Extensions == nil {
+				problem.Extensions = map[string]any{}
+			}
+			problem.Extensions[key] = value
+		}
+	}
+	return problem
+}
+
+// RespondWithProblem will send a reply with an error as RFC 9457 problem details and a HTTP Status code
+//
+// The Content-Type is application/problem+json. See NewProblemDetails for the members that are sent.
+// The request ID given by RequestIDMiddleware is added as the "request_id" extension.
+func RespondWithProblem(w http.ResponseWriter, code int, err error) {
+	problem := NewProblemDetails(code, err)
+	if requestID, ok := requestIDFromWriter(w); ok {
+		if problem.Extensions == nil {
+			problem.Extensions = map[string]any{}
+		}
+		problem.Extensions["request_id"] = requestID
+	}
+	payload, merr := json.Marshal(problem)
+	if merr != nil { // one of the extensions cannot be marshaled
+		problem.Extensions = nil
+		payload, _ = json.Marshal(problem)
+	}
+	writeResponse(w, code, ProblemDetailsContentType, payload)
+}
+
+// GetProblemDetails returns the ProblemDetails themselves
+//
+// implements ProblemDetailer
+func (problem ProblemDetails) GetProblemDetails() ProblemDetails {
+	return problem
+}
+
+// Error returns the Detail of the ProblemDetails, or its Title
+//
+// implements error
+func (problem ProblemDetails) Error() string {
+	if len(problem.Detail) > 0 {
+		return problem.Detail
+	}
+	if len(problem.Title) > 0 {
+		return problem.Title
+	}
+	return http.StatusText(problem.Status)
+}
+
+// MarshalJSON marshals the ProblemDetails to JSON
+//
+// implements json.Marshaler
+func (problem ProblemDetails) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(problem.Extensions)+5)
+	for key, value := range problem.Extensions {
+		members[key] = value
+	}
+	type surrogate ProblemDetails
+	payload, err := json.Marshal(surrogate(problem))
+	if err != nil {
+		return nil, err
+	}
+	var standard map[string]any
+	if err := json.Unmarshal(payload, &standard); err != nil {
+		return nil, err
+	}
+	for key, value := range standard {
+		members[key] = value
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON unmarshals the ProblemDetails from JSON
+//
+// The members that are not standard are stored in the Extensions.
+//
+// implements json.Unmarshaler
+func (problem *ProblemDetails) UnmarshalJSON(payload []byte) error {
+	type surrogate ProblemDetails
+	var inner struct {
+		surrogate
+		Status json.RawMessage `json:"status,omitempty"`
+	}
+	if err := json.Unmarshal(payload, &inner); err != nil {
+		return err
+	}
+	var members map[string]any
+	if err := json.Unmarshal(payload, &members); err != nil {
+		return err
+	}
+	*problem = ProblemDetails(inner.surrogate)
+	if len(inner.Status) > 0 {
+		var status FlexInt
+		if err := json.Unmarshal(inner.Status, &status); err != nil {
+			return err
+		}
+		problem.Status = int(status)
+	}
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+	if len(members) > 0 {
+		problem.Extensions = members
+	}
+	return nil
+}
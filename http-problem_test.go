@@ -0,0 +1,112 @@
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type OutOfCreditError struct {
+	Balance int
+}
+
+func (err OutOfCreditError) Error() string {
+	return fmt.Sprintf("your balance is %d", err.Balance)
+}
+
+func (err OutOfCreditError) GetProblemDetails() core.ProblemDetails {
+	return core.ProblemDetails{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Instance:   "/account/12345/msgs/abc",
+		Extensions: map[string]any{"balance": err.Balance},
+	}
+}
+
+func getProblem(t *testing.T, handler http.HandlerFunc) (*http.Response, map[string]any) {
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.NoErrorf(t, err, "Failed to get %s", server.URL)
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(res.Body)
+	require.NoErrorf(t, err, "Failed to read body of %s", server.URL)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(body, &payload), "Failed to unmarshal JSON")
+	return res, payload
+}
+
+func TestHTTPResponderWithProblem(t *testing.T) {
+	res, payload := getProblem(t, func(res http.ResponseWriter, req *http.Request) {
+		core.RespondWithProblem(res, http.StatusNotFound, fmt.Errorf("Not found"))
+	})
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, core.ProblemDetailsContentType, res.Header.Get("Content-Type"))
+	assert.Equal(t, map[string]any{"title": "Not Found", "status": float64(404), "detail": "Not found"}, payload)
+}
+
+func TestHTTPResponderWithProblemDetailer(t *testing.T) {
+	res, payload := getProblem(t, func(res http.ResponseWriter, req *http.Request) {
+		core.RespondWithProblem(res, http.StatusForbidden, fmt.Errorf("Cannot send message: %w", OutOfCreditError{Balance: 30}))
+	})
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	expected := map[string]any{
+		"type":     "https://example.com/probs/out-of-credit",
+		"title":    "You do not have enough credit.",
+		"status":   float64(403),
+		"detail":   "Cannot send message: your balance is 30",
+		"instance": "/account/12345/msgs/abc",
+		"balance":  float64(30),
+	}
+	assert.Equal(t, expected, payload)
+}
+
+func TestHTTPResponderWithProblemFromWrappedStructuredError(t *testing.T) {
+	_, payload := getProblem(t, func(res http.ResponseWriter, req *http.Request) {
+		err := StructuredError{ID: "1234", Message: "Not found", What: "something", Value: "53c1e09c-bc7e-4989-8bc9-d71acdb0a013"}
+		core.RespondWithProblem(res, http.StatusNotFound, fmt.Errorf("Failed to load: %w", err))
+	})
+	assert.Equal(t, "1234", payload["id"])
+	assert.Equal(t, "something", payload["what"])
+	assert.Equal(t, "53c1e09c-bc7e-4989-8bc9-d71acdb0a013", payload["value"])
+	assert.Equal(t, "Failed to load: Not found", payload["detail"])
+}
+
+func TestHTTPResponderWithWrappedStructuredError(t *testing.T) {
+	_, payload := getProblem(t, func(res http.ResponseWriter, req *http.Request) {
+		err := StructuredError{ID: "1234", Message: "Not found", What: "something"}
+		core.RespondWithError(res, http.StatusNotFound, fmt.Errorf("Failed to load: %w", err))
+	})
+	assert.Equal(t, map[string]any{"error": "Failed to load: Not found", "http_status": "404", "id": "1234", "what": "something"}, payload)
+}
+
+func TestCanMarshalProblemDetails(t *testing.T) {
+	problem := core.ProblemDetails{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     403,
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Extensions: map[string]any{"balance": float64(30), "title": "ignored"},
+	}
+	payload, err := json.Marshal(problem)
+	require.NoError(t, err, "Failed to marshal problem")
+	assert.JSONEq(t, `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","balance":30}`, string(payload))
+
+	var unmarshaled core.ProblemDetails
+	require.NoError(t, json.Unmarshal(payload, &unmarshaled), "Failed to unmarshal problem")
+	problem.Extensions = map[string]any{"balance": float64(30)}
+	assert.Equal(t, problem, unmarshaled)
+	assert.Equal(t, "Your current balance is 30, but that costs 50.", unmarshaled.Error())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"status":"404"}`), &unmarshaled), "Failed to unmarshal problem")
+	assert.Equal(t, 404, unmarshaled.Status)
+	assert.Equal(t, "Not Found", unmarshaled.Error())
+}
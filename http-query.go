@@ -0,0 +1,229 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestParameterError describes a query or path parameter that cannot be bound
+type RequestParameterError struct {
+	In    string `json:"in"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Cause error  `json:"-"`
+}
+
+// RequestParametersError is returned by BindRequest when some parameters cannot be bound
+//
+// It contains all the parameters in error, so handlers can reply once with all of them.
+type RequestParametersError struct {
+	Errors []RequestParameterError
+}
+
+// QueryAsString returns the string value of a query parameter by its name
+//
+// if not present, the fallback value is used
+func QueryAsString(r *http.Request, name, fallback string) string {
+	if value := r.URL.Query().Get(name); len(value) > 0 {
+		return value
+	}
+	return fallback
+}
+
+// QueryAsBool returns the bool value of a query parameter by its name
+//
+// "1", "on", "yes", and "true" are true, "0", "off", "no", and "false" are false.
+// A parameter without a value (e.g.: ?verbose) is true.
+//
+// if not present or invalid, the fallback value is used
+func QueryAsBool(r *http.Request, name string, fallback bool) bool {
+	query := r.URL.Query()
+	if !query.Has(name) {
+		return fallback
+	}
+	if len(query.Get(name)) == 0 {
+		return true
+	}
+	if value, err := parseBool(query.Get(name)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// QueryAsInt returns the int value of a query parameter by its name
+//
+// if not present or invalid, the fallback value is used
+func QueryAsInt(r *http.Request, name string, fallback int) int {
+	return queryAs(r, name, fallback)
+}
+
+// QueryAsTime returns the time value of a query parameter by its name
+//
+// The value is parsed with ParseTime, so "now", "today", "yesterday", or "tomorrow" are valid.
+//
+// if not present or invalid, the fallback value is used
+func QueryAsTime(r *http.Request, name string, fallback time.Time) time.Time {
+	return queryAs(r, name, fallback)
+}
+
+// QueryAsDuration returns the duration value of a query parameter by its name
+//
+// The value is parsed with ParseDuration, so ISO 8601 and Go durations are valid.
+//
+// if not present or invalid, the fallback value is used
+func QueryAsDuration(r *http.Request, name string, fallback time.Duration) time.Duration {
+	return queryAs(r, name, fallback)
+}
+
+// QueryAsUUID returns the UUID value of a query parameter by its name
+//
+// The value is parsed with ParseUUID, so the compact form of EncodeUUID is valid.
+//
+// if not present or invalid, the fallback value is used
+func QueryAsUUID(r *http.Request, name string, fallback uuid.UUID) uuid.UUID {
+	return queryAs(r, name, fallback)
+}
+
+// BindRequest populates a struct from the query and path parameters of a request
+//
+// The fields of the struct are bound to query parameters with the "query" tag and to path parameters
+// (see http.Request.PathValue) with the "path" tag, a "default" tag gives the value to use when the parameter is missing.
+// Nested structs without tags are walked.
+//
+// Values are converted like LoadConfig does. Slices are read from repeated or comma-separated parameters.
+//
+// All the parameters that cannot be bound are reported in a RequestParametersError, which is a 400 Bad Request for ErrorStatus.
+//
+// Example:
+//
+//	type ListOrders struct {
+//		Customer core.UUID `path:"customer"`
+//		Since    core.Time `query:"since" default:"yesterday"`
+//		Limit    int       `query:"limit" default:"20"`
+//		Status   []string  `query:"status"`
+//	}
+//
+//	var params ListOrders
+//	if err := core.BindRequest(r, &params); err != nil {
+//		core.RespondWithInferredProblem(w, err)
+//		return
+//	}
+func BindRequest(r *http.Request, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("target must be a non-nil pointer to a struct")
+	}
+	var parameterErrors []RequestParameterError
+	bindRequest(r, value.Elem(), &parameterErrors)
+	if len(parameterErrors) > 0 {
+		return RequestParametersError{Errors: parameterErrors}
+	}
+	return nil
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err RequestParameterError) Error() string {
+	return fmt.Sprintf(`Invalid %s parameter "%s": %s`, err.In, err.Name, err.Cause)
+}
+
+// Unwrap returns the error that caused this error
+func (err RequestParameterError) Unwrap() error {
+	return err.Cause
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err RequestParametersError) Error() string {
+	messages := make([]string, len(err.Errors))
+	for i, parameterError := range err.Errors {
+		messages[i] = parameterError.Error()
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Unwrap returns the errors of each parameter
+func (err RequestParametersError) Unwrap() []error {
+	errs := make([]error, len(err.Errors))
+	for i, parameterError := range err.Errors {
+		errs[i] = parameterError
+	}
+	return errs
+}
+
+// HTTPStatus returns 400 Bad Request
+//
+// implements HTTPStatuser
+func (err RequestParametersError) HTTPStatus() int {
+	return http.StatusBadRequest
+}
+
+// GetProblemDetails returns the ProblemDetails of this error, the parameters in error are given as the "errors" extension
+//
+// implements ProblemDetailer
+func (err RequestParametersError) GetProblemDetails() ProblemDetails {
+	type invalidParameter struct {
+		RequestParameterError
+		Detail string `json:"detail"`
+	}
+	parameters := make([]invalidParameter, len(err.Errors))
+	for i, parameterError := range err.Errors {
+		parameters[i] = invalidParameter{parameterError, parameterError.Cause.Error()}
+	}
+	return ProblemDetails{
+		Status:     http.StatusBadRequest,
+		Detail:     err.Error(),
+		Extensions: map[string]any{"errors": parameters},
+	}
+}
+
+func queryAs[T any](r *http.Request, name string, fallback T) T {
+	if raw := r.URL.Query().Get(name); len(raw) > 0 {
+		var value T
+		if err := parseValue(reflect.ValueOf(&value).Elem(), raw); err == nil {
+			return value
+		}
+	}
+	return fallback
+}
+
+func bindRequest(r *http.Request, target reflect.Value, parameterErrors *[]RequestParameterError) {
+	query := r.URL.Query()
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		var in, name, raw string
+		if name = field.Tag.Get("path"); len(name) > 0 {
+			in, raw = "path", r.PathValue(name)
+		} else if name = field.Tag.Get("query"); len(name) > 0 {
+			in, raw = "query", query.Get(name)
+			if field.Type.Kind() == reflect.Slice && len(query[name]) > 1 {
+				raw = strings.Join(query[name], ",")
+			}
+		} else {
+			if field.Type.Kind() == reflect.Struct {
+				bindRequest(r, target.Field(i), parameterErrors)
+			}
+			continue
+		}
+		if len(raw) == 0 {
+			var found bool
+			if raw, found = field.Tag.Lookup("default"); !found {
+				continue
+			}
+		}
+		if err := parseValue(target.Field(i), raw); err != nil {
+			*parameterErrors = append(*parameterErrors, RequestParameterError{In: in, Name: name, Value: raw, Cause: err})
+		}
+	}
+}
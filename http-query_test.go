@@ -0,0 +1,114 @@
+package core_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Paging struct {
+	Limit  int `query:"limit" default:"20"`
+	Offset int `query:"offset"`
+}
+
+type ListOrdersParams struct {
+	Customer core.UUID     `path:"customer"`
+	Since    core.Time     `query:"since" default:"yesterday"`
+	Timeout  core.Duration `query:"timeout" default:"PT30S"`
+	Verbose  bool          `query:"verbose"`
+	Status   []string      `query:"status"`
+	Paging
+}
+
+func bind(t *testing.T, pattern, target string, params any) error {
+	var err error
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		err = core.BindRequest(r, params)
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	return err
+}
+
+func TestCanGetQueryAsValues(t *testing.T) {
+	id := uuid.New()
+	request := httptest.NewRequest(http.MethodGet, "/?name=john&verbose&limit=10&bad=abc&delay=PT5M&id="+core.EncodeUUID(id)+"&since=2026-01-02T03:04:05Z", nil)
+	assert.Equal(t, "john", core.QueryAsString(request, "name", "nobody"))
+	assert.Equal(t, "nobody", core.QueryAsString(request, "missing", "nobody"))
+	assert.True(t, core.QueryAsBool(request, "verbose", false))
+	assert.True(t, core.QueryAsBool(request, "bad", true))
+	assert.Equal(t, 10, core.QueryAsInt(request, "limit", 20))
+	assert.Equal(t, 20, core.QueryAsInt(request, "bad", 20))
+	assert.Equal(t, 20, core.QueryAsInt(request, "missing", 20))
+	assert.Equal(t, 5*time.Minute, core.QueryAsDuration(request, "delay", time.Second))
+	assert.Equal(t, id, core.QueryAsUUID(request, "id", uuid.Nil))
+	assert.Equal(t, uuid.Nil, core.QueryAsUUID(request, "bad", uuid.Nil))
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), core.QueryAsTime(request, "since", time.Time{}).UTC())
+}
+
+func TestCanGetQueryAsRelativeTime(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/?since=yesterday", nil)
+	expected := core.Now().BeginOfDay().Yesterday()
+	assert.Equal(t, expected.AsTime(), core.QueryAsTime(request, "since", time.Time{}))
+}
+
+func TestCanBindRequest(t *testing.T) {
+	customer := core.NewUUID()
+	var params ListOrdersParams
+	err := bind(t, "GET /customers/{customer}/orders", "/customers/"+customer.String()+"/orders?timeout=1m&verbose=yes&status=open&status=closed&offset=40", &params)
+	require.NoError(t, err)
+	assert.Equal(t, customer, params.Customer)
+	assert.Equal(t, core.Now().BeginOfDay().Yesterday(), params.Since)
+	assert.Equal(t, time.Minute, params.Timeout.AsDuration())
+	assert.True(t, params.Verbose)
+	assert.Equal(t, []string{"open", "closed"}, params.Status)
+	assert.Equal(t, 20, params.Limit)
+	assert.Equal(t, 40, params.Offset)
+}
+
+func TestCanBindRequestWithCompactUUID(t *testing.T) {
+	customer := core.NewUUID()
+	var params ListOrdersParams
+	err := bind(t, "GET /customers/{customer}/orders", "/customers/"+core.EncodeUUID(uuid.UUID(customer))+"/orders?status=open,closed", &params)
+	require.NoError(t, err)
+	assert.Equal(t, customer, params.Customer)
+	assert.Equal(t, []string{"open", "closed"}, params.Status)
+}
+
+func TestShouldFailBindingRequestWithInvalidParameters(t *testing.T) {
+	var params ListOrdersParams
+	err := bind(t, "GET /customers/{customer}/orders", "/customers/john/orders?since=someday&limit=ten&verbose=maybe", &params)
+	require.Error(t, err)
+	var parametersError core.RequestParametersError
+	require.ErrorAs(t, err, &parametersError)
+	require.Len(t, parametersError.Errors, 4)
+	assert.Equal(t, "path", parametersError.Errors[0].In)
+	assert.Equal(t, "customer", parametersError.Errors[0].Name)
+	assert.Equal(t, "john", parametersError.Errors[0].Value)
+	assert.Equal(t, "since", parametersError.Errors[1].Name)
+	assert.Equal(t, "verbose", parametersError.Errors[2].Name)
+	assert.Equal(t, "limit", parametersError.Errors[3].Name)
+	assert.Equal(t, http.StatusBadRequest, core.ErrorStatus(err))
+
+	recorder := httptest.NewRecorder()
+	core.RespondWithInferredProblem(recorder, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.Len(t, problem["errors"], 4)
+	assert.Equal(t, "query", problem["errors"].([]any)[3].(map[string]any)["in"])
+	assert.Equal(t, "limit", problem["errors"].([]any)[3].(map[string]any)["name"])
+	assert.Equal(t, "ten", problem["errors"].([]any)[3].(map[string]any)["value"])
+}
+
+func TestShouldFailBindingRequestWithInvalidTarget(t *testing.T) {
+	var params ListOrdersParams
+	assert.Error(t, bind(t, "GET /", "/", params))
+}
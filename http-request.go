@@ -0,0 +1,286 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeOptions configures DecodeJSONBody
+type DecodeOptions struct {
+	// MaxBytes is the maximum size of the body, DefaultMaxBodyBytes is used when it is not positive
+	MaxBytes int64
+
+	// DisallowUnknownFields rejects bodies with fields that are not in the target type
+	DisallowUnknownFields bool
+
+	// DisallowTrailingData rejects bodies with data after the JSON value
+	DisallowTrailingData bool
+}
+
+// Validator describes payloads that can validate themselves after being decoded
+type Validator interface {
+	Validate() error
+}
+
+// RequestBodyError is returned by DecodeJSONBody when the body of a request cannot be decoded
+//
+// What contains the path of the field that is wrong, if any (e.g.: "items.0.count").
+// Status is the HTTP status code to reply with (400, 413 or 415), it is used by ErrorStatus.
+type RequestBodyError struct {
+	Status  int
+	What    string
+	Message string
+	Cause   error
+}
+
+// DefaultMaxBodyBytes is the maximum size of a body read by DecodeJSONBody when DecodeOptions.MaxBytes is not set
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// DecodeJSONBody decodes the JSON body of a request
+//
+// The Content-Type of the request must be application/json (or a +json media type), and its body
+// cannot be larger than DecodeOptions.MaxBytes. If the decoded payload implements Validator, it is validated.
+//
+// Errors are RequestBodyError with a message that can be sent to the client as is:
+//
+//	user, err := core.DecodeJSONBody[User](w, r, core.DecodeOptions{DisallowUnknownFields: true})
+//	if err != nil {
+//		core.RespondWithInferredError(w, err) // 400, 413 or 415
+//		return
+//	}
+func DecodeJSONBody[T any](w http.ResponseWriter, r *http.Request, options DecodeOptions) (payload T, err error) {
+	if contentType := r.Header.Get("Content-Type"); !isJSONContentType(contentType) {
+		return payload, RequestBodyError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: fmt.Sprintf(`Content-Type must be application/json, not "%s"`, contentType),
+		}
+	}
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultMaxBodyBytes
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, options.MaxBytes))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return payload, RequestBodyError{
+				Status:  http.StatusRequestEntityTooLarge,
+				Message: fmt.Sprintf("Request body must not be larger than %d bytes", maxBytesError.Limit),
+				Cause:   err,
+			}
+		}
+		return payload, RequestBodyError{Status: http.StatusBadRequest, Message: "Request body cannot be read", Cause: err}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if options.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err = decoder.Decode(&payload); err != nil {
+		return payload, newRequestBodyError(body, reflect.TypeOf(&payload).Elem(), err)
+	}
+	if options.DisallowTrailingData {
+		if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+			return payload, RequestBodyError{Status: http.StatusBadRequest, Message: "Request body must contain a single JSON value", Cause: err}
+		}
+	}
+	if validator, ok := any(&payload).(Validator); ok {
+		if err = validator.Validate(); err != nil {
+			return payload, RequestBodyError{Status: http.StatusBadRequest, Message: err.Error(), Cause: err}
+		}
+	}
+	return payload, nil
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err RequestBodyError) Error() string {
+	return err.Message
+}
+
+// Unwrap returns the error that caused this error, if any
+func (err RequestBodyError) Unwrap() error {
+	return err.Cause
+}
+
+// HTTPStatus returns the HTTP status code of this error
+//
+// implements HTTPStatuser
+func (err RequestBodyError) HTTPStatus() int {
+	return err.Status
+}
+
+// GetProblemDetails returns the ProblemDetails of this error, the path of the wrong field is given as the "field" extension
+//
+// implements ProblemDetailer
+func (err RequestBodyError) GetProblemDetails() ProblemDetails {
+	problem := ProblemDetails{Status: err.Status, Detail: err.Message}
+	if len(err.What) > 0 {
+		problem.Extensions = map[string]any{"field": err.What}
+	}
+	return problem
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// newRequestBodyError turns a json decoding error into a RequestBodyError with a friendly message
+func newRequestBodyError(body []byte, payloadType reflect.Type, err error) RequestBodyError {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return RequestBodyError{Status: http.StatusBadRequest, Message: "Request body must not be empty", Cause: err}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return RequestBodyError{Status: http.StatusBadRequest, Message: "Request body contains badly-formed JSON", Cause: err}
+	case errors.As(err, &syntaxError):
+		return RequestBodyError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("Request body contains badly-formed JSON (at position %d)", syntaxError.Offset),
+			Cause:   err,
+		}
+	case errors.As(err, &typeError):
+		return RequestBodyError{
+			Status:  http.StatusBadRequest,
+			What:    typeError.Field,
+			Message: fmt.Sprintf(`Field "%s" must be %s, not %s`, typeError.Field, describeJSONType(typeError.Type), withArticle(typeError.Value)),
+			Cause:   err,
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		return RequestBodyError{Status: http.StatusBadRequest, What: field, Message: fmt.Sprintf(`Field "%s" is not allowed`, field), Cause: err}
+	}
+
+	// The error comes from an Unmarshaler, which json does not locate
+	if path, fieldType, found := locateJSONError(body, payloadType, nil); found && len(path) > 0 {
+		return RequestBodyError{
+			Status:  http.StatusBadRequest,
+			What:    path,
+			Message: fmt.Sprintf(`Field "%s" must be %s`, path, describeJSONType(fieldType)),
+			Cause:   err,
+		}
+	}
+	return RequestBodyError{Status: http.StatusBadRequest, Message: "Request body is invalid: " + err.Error(), Cause: err}
+}
+
+// locateJSONError finds the innermost field of payload that fails to decode into the given type
+func locateJSONError(payload []byte, target reflect.Type, path []string) (string, reflect.Type, bool) {
+	for target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	if err := json.Unmarshal(payload, reflect.New(target).Interface()); err == nil {
+		return "", nil, false
+	}
+	if !reflect.PointerTo(target).Implements(jsonUnmarshalerType) {
+		switch target.Kind() {
+		case reflect.Struct:
+			var members map[string]json.RawMessage
+			if json.Unmarshal(payload, &members) == nil {
+				for _, field := range reflect.VisibleFields(target) {
+					if !field.IsExported() || field.Anonymous {
+						continue
+					}
+					name := jsonFieldName(field)
+					if name == "-" {
+						continue
+					}
+					for key, member := range members {
+						if strings.EqualFold(key, name) {
+							if found, fieldType, ok := locateJSONError(member, field.Type, append(path, name)); ok {
+								return found, fieldType, true
+							}
+						}
+					}
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			var items []json.RawMessage
+			if json.Unmarshal(payload, &items) == nil {
+				for index, item := range items {
+					if found, fieldType, ok := locateJSONError(item, target.Elem(), append(path, strconv.Itoa(index))); ok {
+						return found, fieldType, true
+					}
+				}
+			}
+		case reflect.Map:
+			var members map[string]json.RawMessage
+			if json.Unmarshal(payload, &members) == nil {
+				for key, member := range members {
+					if found, fieldType, ok := locateJSONError(member, target.Elem(), append(path, key)); ok {
+						return found, fieldType, true
+					}
+				}
+			}
+		}
+	}
+	return strings.Join(path, "."), target, true
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func jsonFieldName(field reflect.StructField) string {
+	if tag, found := field.Tag.Lookup("json"); found {
+		if name, _, _ := strings.Cut(tag, ","); len(name) > 0 {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// describeJSONType describes what a JSON value must look like to be decoded into the given type
+func describeJSONType(target reflect.Type) string {
+	if target == nil {
+		return "valid"
+	}
+	for target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	switch target {
+	case reflect.TypeOf(Time{}):
+		return `an RFC 3339 time (e.g. "2006-01-02T15:04:05Z")`
+	case reflect.TypeOf(Duration(0)):
+		return `a number of milliseconds or a duration (e.g. "PT5M" or "5m")`
+	case reflect.TypeOf(FlexInt(0)), reflect.TypeOf(FlexInt8(0)), reflect.TypeOf(FlexInt16(0)), reflect.TypeOf(FlexInt32(0)), reflect.TypeOf(FlexInt64(0)):
+		return "an integer or a string containing an integer"
+	case reflect.TypeOf(URL{}):
+		return "a URL"
+	case reflect.TypeOf(UUID{}):
+		return "a UUID"
+	}
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	}
+	return "valid"
+}
+
+// withArticle prefixes the JSON type of an UnmarshalTypeError value with an article (e.g.: "an array", "a number")
+func withArticle(jsonType string) string {
+	jsonType, _, _ = strings.Cut(jsonType, " ") // numbers come as "number 3.14"
+	switch jsonType {
+	case "array", "object":
+		return "an " + jsonType
+	}
+	return "a " + jsonType
+}
@@ -0,0 +1,123 @@
+package core_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type OrderLine struct {
+	Product  string       `json:"product"`
+	Quantity int          `json:"quantity"`
+	Discount core.FlexInt `json:"discount"`
+}
+
+type OrderRequest struct {
+	Customer string        `json:"customer"`
+	DueAt    core.Time     `json:"dueAt"`
+	Delay    core.Duration `json:"delay"`
+	Lines    []OrderLine   `json:"lines"`
+}
+
+func (order OrderRequest) Validate() error {
+	if len(order.Customer) == 0 {
+		return errors.New("customer is required")
+	}
+	return nil
+}
+
+func decodeOrder(t *testing.T, contentType, body string, options core.DecodeOptions) (OrderRequest, error) {
+	request := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	if len(contentType) > 0 {
+		request.Header.Set("Content-Type", contentType)
+	}
+	return core.DecodeJSONBody[OrderRequest](httptest.NewRecorder(), request, options)
+}
+
+func TestCanDecodeJSONBody(t *testing.T) {
+	order, err := decodeOrder(t, "application/json; charset=utf-8", `{"customer": "john", "dueAt": "2026-01-02T03:04:05Z", "delay": "PT5M", "lines": [{"product": "apple", "quantity": 2, "discount": "10"}]}`, core.DecodeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "john", order.Customer)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), order.DueAt.AsTime())
+	assert.Equal(t, 5*time.Minute, order.Delay.AsDuration())
+	require.Len(t, order.Lines, 1)
+	assert.Equal(t, core.FlexInt(10), order.Lines[0].Discount)
+}
+
+func TestCanDecodeJSONBodyWithJSONSuffixContentType(t *testing.T) {
+	_, err := decodeOrder(t, "application/vnd.api+json", `{"customer": "john"}`, core.DecodeOptions{})
+	assert.NoError(t, err)
+}
+
+func TestShouldFailDecodingJSONBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		options     core.DecodeOptions
+		status      int
+		what        string
+		message     string
+	}{
+		{"no content type", "", `{"customer": "john"}`, core.DecodeOptions{}, http.StatusUnsupportedMediaType, "", `Content-Type must be application/json, not ""`},
+		{"wrong content type", "text/plain", `{"customer": "john"}`, core.DecodeOptions{}, http.StatusUnsupportedMediaType, "", `Content-Type must be application/json, not "text/plain"`},
+		{"too large", "application/json", `{"customer": "john"}`, core.DecodeOptions{MaxBytes: 10}, http.StatusRequestEntityTooLarge, "", "Request body must not be larger than 10 bytes"},
+		{"empty", "application/json", ``, core.DecodeOptions{}, http.StatusBadRequest, "", "Request body must not be empty"},
+		{"truncated", "application/json", `{"customer": "jo`, core.DecodeOptions{}, http.StatusBadRequest, "", "Request body contains badly-formed JSON"},
+		{"syntax", "application/json", `{"customer": john}`, core.DecodeOptions{}, http.StatusBadRequest, "", "Request body contains badly-formed JSON (at position 14)"},
+		{"wrong type", "application/json", `{"customer": "john", "lines": [{"quantity": "two"}]}`, core.DecodeOptions{}, http.StatusBadRequest, "lines.0.quantity", `Field "lines.0.quantity" must be an integer, not a string`},
+		{"wrong array", "application/json", `{"customer": "john", "lines": {}}`, core.DecodeOptions{}, http.StatusBadRequest, "lines", `Field "lines" must be an array, not an object`},
+		{"wrong time", "application/json", `{"customer": "john", "dueAt": "tomorrow"}`, core.DecodeOptions{}, http.StatusBadRequest, "dueAt", `Field "dueAt" must be an RFC 3339 time (e.g. "2006-01-02T15:04:05Z")`},
+		{"wrong duration", "application/json", `{"customer": "john", "delay": true}`, core.DecodeOptions{}, http.StatusBadRequest, "delay", `Field "delay" must be a number of milliseconds or a duration (e.g. "PT5M" or "5m")`},
+		{"wrong flexint", "application/json", `{"customer": "john", "lines": [{"discount": 1}, {"discount": "ten"}]}`, core.DecodeOptions{}, http.StatusBadRequest, "lines.1.discount", `Field "lines.1.discount" must be an integer or a string containing an integer`},
+		{"unknown field", "application/json", `{"customer": "john", "vip": true}`, core.DecodeOptions{DisallowUnknownFields: true}, http.StatusBadRequest, "vip", `Field "vip" is not allowed`},
+		{"trailing data", "application/json", `{"customer": "john"} {}`, core.DecodeOptions{DisallowTrailingData: true}, http.StatusBadRequest, "", "Request body must contain a single JSON value"},
+		{"invalid", "application/json", `{"lines": []}`, core.DecodeOptions{}, http.StatusBadRequest, "", "customer is required"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decodeOrder(t, test.contentType, test.body, test.options)
+			require.Error(t, err)
+			var bodyError core.RequestBodyError
+			require.ErrorAs(t, err, &bodyError)
+			assert.Equal(t, test.status, bodyError.Status)
+			assert.Equal(t, test.what, bodyError.What)
+			assert.Equal(t, test.message, bodyError.Error())
+			assert.Equal(t, test.status, core.ErrorStatus(err))
+		})
+	}
+}
+
+func TestCanDecodeJSONBodyWithTrailingData(t *testing.T) {
+	order, err := decodeOrder(t, "application/json", `{"customer": "john"} {}`, core.DecodeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "john", order.Customer)
+}
+
+func TestCanRespondWithRequestBodyError(t *testing.T) {
+	_, err := decodeOrder(t, "application/json", `{"customer": "john", "lines": [{"quantity": "two"}]}`, core.DecodeOptions{})
+	require.Error(t, err)
+
+	recorder := httptest.NewRecorder()
+	core.RespondWithInferredError(recorder, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload))
+	assert.Equal(t, "400", payload["http_status"])
+	assert.Equal(t, "lines.0.quantity", payload["what"])
+
+	recorder = httptest.NewRecorder()
+	core.RespondWithInferredProblem(recorder, err)
+	var problem core.ProblemDetails
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "lines.0.quantity", problem.Extensions["field"])
+}
@@ -1,46 +1,32 @@
 package core
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"html/template"
+	"iter"
 	"net/http"
-	"reflect"
 	"strconv"
-
-	"github.com/google/uuid"
+	"strings"
+	"time"
 )
 
 // RespondWithError will send a reply with an error as JSON and a HTTP Status code
+//
+// The ID, What, and Value fields of the error, or of the errors it wraps, are added to the reply.
+// The request ID given by RequestIDMiddleware is added as "request_id".
 func RespondWithError(w http.ResponseWriter, code int, err error) {
 	props := map[string]string{
 		"http_status": strconv.Itoa(code),
 		"error":       err.Error(),
 	}
-
-	var field reflect.Value
-	errValue := reflect.ValueOf(err)
-
-	// detect errors like fmt.Errorf()
-	if errValue.Type().Kind() == reflect.Pointer {
-		errValue = errValue.Elem()
-	}
-
-	field = errValue.FieldByName("ID")
-	if field.IsValid() && field.Type().Kind().String() == "string" && field.Len() > 0 {
-		props["id"] = field.String()
-	}
-	field = errValue.FieldByName("What")
-	if field.IsValid() && field.Type().Kind().String() == "string" && field.Len() > 0 {
-		props["what"] = field.String()
+	for key, value := range errorProperties(err) {
+		props[key] = value
 	}
-	if field = errValue.FieldByName("Value"); field.IsValid() {
-		if stringer, ok := field.Interface().(interface{ String() string }); ok {
-			props["value"] = stringer.String()
-		} else if identifiable, ok := field.Interface().(interface{ GetID() uuid.UUID }); ok {
-			props["value"] = identifiable.GetID().String()
-		} else if field.Type().Kind().String() == "string" && field.Len() > 0 {
-			props["value"] = field.String()
-		}
+	if requestID, ok := requestIDFromWriter(w); ok {
+		props["request_id"] = requestID
 	}
 	RespondWithJSON(w, code, props)
 }
@@ -48,18 +34,240 @@ func RespondWithError(w http.ResponseWriter, code int, err error) {
 // RespondWithJSON will send a reply with a JSON payload and a HTTP Status code
 //
 // The payload will be marshaled using the standard json.Marshal function.
+// If the payload cannot be marshaled, a 500 Internal Server Error is sent instead.
 func RespondWithJSON(w http.ResponseWriter, code int, payload any) {
-	response, _ := json.Marshal(payload)
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(code)
-	_, _ = w.Write(response)
+	RespondWithEncoder(w, code, JSONEncoder{}, payload)
 }
 
 // RespondWithHTMLTemplate will send a reply with a HTML payload generated from an HTML Template and a HTTP Status code
+//
+// If the template fails, a 500 Internal Server Error is sent instead.
 func RespondWithHTMLTemplate(w http.ResponseWriter, code int, template *template.Template, name string, data any) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := template.ExecuteTemplate(w, name, data); err != nil {
+	RespondWithEncoder(w, code, HTMLTemplateEncoder{Template: template, Name: name}, data)
+}
+
+// RespondWithEncoder will send a reply with a payload encoded by the given ResponseEncoder and a HTTP Status code
+//
+// The payload is encoded in a buffer first, so nothing is written if the encoder fails,
+// and a 500 Internal Server Error is sent instead. The Content-Length header is set.
+//
+// Example:
+//
+//	encoder, err := core.JSONEncoderFromRequest(r) // supports ?pretty and ?callback=
+//	if err != nil {
+//		core.RespondWithError(w, http.StatusBadRequest, err)
+//		return
+//	}
+//	core.RespondWithEncoder(w, http.StatusOK, encoder, payload)
+func RespondWithEncoder(w http.ResponseWriter, code int, encoder ResponseEncoder, payload any) {
+	var buffer bytes.Buffer
+	if err := encoder.Encode(&buffer, payload); err != nil {
 		RespondWithError(w, http.StatusInternalServerError, err)
 		return
 	}
+	writeResponse(w, code, encoder.ContentType(), buffer.Bytes())
+}
+
+// writeResponse writes a fully encoded response
+func writeResponse(w http.ResponseWriter, code int, contentType string, response []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+	w.WriteHeader(code)
+	_, _ = w.Write(response)
+}
+
+// ServerSentEvent is an event sent by RespondWithServerSentEvents
+//
+// The Data is sent as is if it is a string, otherwise it is marshaled as JSON.
+// The ID, Event and Retry are optional.
+type ServerSentEvent struct {
+	ID    string
+	Event string
+	Data  any
+	Retry time.Duration
+}
+
+// RespondWithNDJSON will stream items as Newline Delimited JSON with a HTTP Status code
+//
+// Each item is marshaled on its own line and flushed to the client.
+// The stream stops when the request context is done, its error is returned,
+// or when an item cannot be marshaled, the marshal error is returned.
+//
+// As the headers are sent before the first item, errors cannot be sent to the client.
+func RespondWithNDJSON[T any](w http.ResponseWriter, r *http.Request, code int, items iter.Seq[T]) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(code)
+	controller := http.NewResponseController(w)
+	for item := range items {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		line, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		_ = controller.Flush()
+	}
+	return r.Context().Err()
+}
+
+// RespondWithNDJSONFromChannel will stream the items of a channel as Newline Delimited JSON with a HTTP Status code
+//
+// The stream stops when the channel is closed or when the request context is done.
+// See RespondWithNDJSON.
+func RespondWithNDJSONFromChannel[T any](w http.ResponseWriter, r *http.Request, code int, items <-chan T) error {
+	return RespondWithNDJSON(w, r, code, channelSeq(r.Context(), items))
+}
+
+// RespondWithJSONArray will stream items as a JSON array with a HTTP Status code
+//
+// The items are marshaled and flushed one by one, so large arrays are never held in memory.
+// The stream stops when the request context is done, its error is returned,
+// or when an item cannot be marshaled, the marshal error is returned.
+// In both cases the array is not closed, so clients do not mistake a truncated array for a complete one.
+func RespondWithJSONArray[T any](w http.ResponseWriter, r *http.Request, code int, items iter.Seq[T]) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	controller := http.NewResponseController(w)
+	separator := []byte("[")
+	for item := range items {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		payload, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(append(separator, payload...)); err != nil {
+			return err
+		}
+		_ = controller.Flush()
+		separator = []byte(",")
+	}
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	if separator[0] == '[' { // no item
+		_, err := w.Write([]byte("[]"))
+		return err
+	}
+	_, err := w.Write([]byte("]"))
+	return err
+}
+
+// RespondWithServerSentEvents will stream the events of a channel as Server-Sent Events
+//
+// If keepAlive is positive, a comment is sent when no event was sent for that duration,
+// so proxies do not close the connection.
+//
+// The stream stops when the channel is closed, or when the request context is done, its error is returned then.
+// If an event cannot be marshaled, it is skipped and the marshal error is returned at the end of the stream.
+func RespondWithServerSentEvents(w http.ResponseWriter, r *http.Request, events <-chan ServerSentEvent, keepAlive time.Duration) error {
+	return RespondWithServerSentEventsWithClock(RealClock{}, w, r, events, keepAlive)
+}
+
+// RespondWithServerSentEventsWithClock will stream the events of a channel as Server-Sent Events, the keep-alive ticks with the given Clock
+//
+// See RespondWithServerSentEvents.
+func RespondWithServerSentEventsWithClock(clock Clock, w http.ResponseWriter, r *http.Request, events <-chan ServerSentEvent, keepAlive time.Duration) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(w)
+	_ = controller.Flush()
+
+	var ticker Ticker
+	var keepAliveC <-chan time.Time
+	if keepAlive > 0 {
+		ticker = clock.NewTicker(keepAlive)
+		defer ticker.Stop()
+		keepAliveC = ticker.C()
+	}
+
+	var errs []error
+	for {
+		select {
+		case <-r.Context().Done():
+			return errors.Join(append(errs, r.Context().Err())...)
+		case <-keepAliveC:
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return err
+			}
+			_ = controller.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return errors.Join(errs...)
+			}
+			payload, err := event.encode()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if _, err := w.Write(payload); err != nil {
+				return err
+			}
+			_ = controller.Flush()
+			if ticker != nil {
+				ticker.Reset(keepAlive)
+			}
+		}
+	}
+}
+
+// sseFieldReplacer removes the characters that would end an id or event field, or make it ignored
+var sseFieldReplacer = strings.NewReplacer("\r", "", "\n", "", "\x00", "")
+
+// sseLineReplacer normalizes the line endings of the data, "\r\n", "\r", and "\n" all end a line in a stream
+var sseLineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// encode encodes the event in the text/event-stream format
+func (event ServerSentEvent) encode() ([]byte, error) {
+	var data string
+	switch value := event.Data.(type) {
+	case string:
+		data = value
+	case []byte:
+		data = string(value)
+	default:
+		payload, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		data = string(payload)
+	}
+	var buffer bytes.Buffer
+	if len(event.ID) > 0 {
+		buffer.WriteString("id: " + sseFieldReplacer.Replace(event.ID) + "\n")
+	}
+	if len(event.Event) > 0 {
+		buffer.WriteString("event: " + sseFieldReplacer.Replace(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		buffer.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(sseLineReplacer.Replace(data), "\n") {
+		buffer.WriteString("data: " + line + "\n")
+	}
+	buffer.WriteString("\n")
+	return buffer.Bytes(), nil
+}
+
+// channelSeq iterates over a channel until it is closed or the context is done
+func channelSeq[T any](ctx context.Context, items <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-items:
+				if !ok || !yield(item) {
+					return
+				}
+			}
+		}
+	}
 }
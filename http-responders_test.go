@@ -1,13 +1,16 @@
 package core_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/gildas/go-core"
 	"github.com/google/uuid"
@@ -185,3 +188,265 @@ func TestHTTPResponderWithHTMLTemplateError(t *testing.T) {
 	require.NoError(t, err, "Failed to unmarshal JSON")
 	assert.Equal(t, map[string]string{"error": `html/template: "nowhere" is undefined`, "http_status": "500"}, payload)
 }
+
+func TestHTTPResponderWithHTMLTemplateErrorAfterOutput(t *testing.T) {
+	page := template.Must(template.New("page").Parse("<html><body><h1>Hello {{.Name.First}}</h1></body></html>"))
+	recorder := httptest.NewRecorder()
+	core.RespondWithHTMLTemplate(recorder, http.StatusCreated, page, "page", map[string]string{"Name": "world"})
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.NotContains(t, recorder.Body.String(), "<html>")
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload), "Failed to unmarshal JSON")
+	assert.Equal(t, "500", payload["http_status"])
+}
+
+func TestHTTPResponderWithHTMLTemplateSendsStatusCode(t *testing.T) {
+	page := template.Must(template.New("page").Parse("<h1>Hello {{.Name}}</h1>"))
+	recorder := httptest.NewRecorder()
+	core.RespondWithHTMLTemplate(recorder, http.StatusCreated, page, "page", map[string]string{"Name": "world"})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "20", recorder.Header().Get("Content-Length"))
+}
+
+func TestHTTPResponderWithJSONSetsContentLength(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	core.RespondWithJSON(recorder, http.StatusOK, map[string]string{"hello": "world"})
+	assert.Equal(t, `{"hello":"world"}`, recorder.Body.String())
+	assert.Equal(t, "17", recorder.Header().Get("Content-Length"))
+}
+
+func TestHTTPResponderWithJSONMarshalError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	core.RespondWithJSON(recorder, http.StatusOK, map[string]any{"channel": make(chan int)})
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload), "Failed to unmarshal JSON")
+	assert.Equal(t, "500", payload["http_status"])
+	assert.Contains(t, payload["error"], "unsupported type")
+}
+
+func TestHTTPResponderWithPrettyJSON(t *testing.T) {
+	encoder, err := core.JSONEncoderFromRequest(httptest.NewRequest(http.MethodGet, "/?pretty", nil))
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	core.RespondWithEncoder(recorder, http.StatusOK, encoder, map[string]string{"hello": "world"})
+	assert.Equal(t, "{\n  \"hello\": \"world\"\n}", recorder.Body.String())
+
+	encoder, err = core.JSONEncoderFromRequest(httptest.NewRequest(http.MethodGet, "/?pretty=false", nil))
+	require.NoError(t, err)
+	assert.Empty(t, encoder.Indent)
+}
+
+func TestHTTPResponderWithJSONP(t *testing.T) {
+	encoder, err := core.JSONEncoderFromRequest(httptest.NewRequest(http.MethodGet, "/?callback=app.handle", nil))
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	core.RespondWithEncoder(recorder, http.StatusOK, encoder, map[string]string{"hello": "world"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/javascript; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `/**/app.handle({"hello":"world"});`, recorder.Body.String())
+}
+
+func TestShouldFailHTTPResponderWithInvalidJSONPCallback(t *testing.T) {
+	_, err := core.JSONEncoderFromRequest(httptest.NewRequest(http.MethodGet, "/?callback=alert(1)", nil))
+	require.Error(t, err)
+	var callbackError core.InvalidJSONPCallbackError
+	require.ErrorAs(t, err, &callbackError)
+	assert.Equal(t, "alert(1)", callbackError.Callback)
+	assert.Equal(t, http.StatusBadRequest, core.ErrorStatus(err))
+
+	recorder := httptest.NewRecorder()
+	core.RespondWithEncoder(recorder, http.StatusOK, core.JSONEncoder{Callback: "<script>"}, "hello")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestHTTPResponderWithNDJSON(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithNDJSON(recorder, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, slices.Values([]Stuff{{ID: uuid.Nil}, {ID: uuid.Nil}}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+	assert.True(t, recorder.Flushed)
+	expected := `{"ID":"00000000-0000-0000-0000-000000000000"}` + "\n"
+	assert.Equal(t, expected+expected, recorder.Body.String())
+}
+
+func TestHTTPResponderWithNDJSONFromChannel(t *testing.T) {
+	items := make(chan int, 3)
+	items <- 1
+	items <- 2
+	items <- 3
+	close(items)
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithNDJSONFromChannel(recorder, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, items)
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n", recorder.Body.String())
+}
+
+// cancellingRecorder cancels the request context after the first write
+type cancellingRecorder struct {
+	*httptest.ResponseRecorder
+	cancel context.CancelFunc
+}
+
+func (recorder cancellingRecorder) Write(payload []byte) (int, error) {
+	defer recorder.cancel()
+	return recorder.ResponseRecorder.Write(payload)
+}
+
+func TestHTTPResponderWithNDJSONStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	items := make(chan int, 1)
+	items <- 1
+	recorder := cancellingRecorder{httptest.NewRecorder(), cancel}
+	err := core.RespondWithNDJSONFromChannel(recorder, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), http.StatusOK, items)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "1\n", recorder.Body.String())
+}
+
+func TestHTTPResponderWithJSONArray(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithJSONArray(recorder, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, slices.Values([]string{"one", "two"}))
+	require.NoError(t, err)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `["one","two"]`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	err = core.RespondWithJSONArray(recorder, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, slices.Values([]string{}))
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, recorder.Body.String())
+}
+
+func TestHTTPResponderWithJSONArrayStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	items := func(yield func(int) bool) {
+		for i := 1; ; i++ {
+			if i == 3 {
+				cancel()
+			}
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithJSONArray(recorder, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), http.StatusOK, items)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, `[1,2`, recorder.Body.String())
+}
+
+func TestShouldFailHTTPResponderWithJSONArrayWithUnmarshalableItem(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithJSONArray(recorder, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, slices.Values([]any{1, make(chan int)}))
+	assert.Error(t, err)
+	assert.Equal(t, `[1`, recorder.Body.String())
+}
+
+func TestHTTPResponderWithServerSentEvents(t *testing.T) {
+	events := make(chan core.ServerSentEvent, 3)
+	events <- core.ServerSentEvent{ID: "1", Event: "greeting", Data: "hello\nworld", Retry: 5 * time.Second}
+	events <- core.ServerSentEvent{Data: map[string]int{"count": 2}}
+	events <- core.ServerSentEvent{Data: make(chan int)}
+	close(events)
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithServerSentEvents(recorder, httptest.NewRequest(http.MethodGet, "/", nil), events, 0)
+	assert.Error(t, err, "The unmarshalable event should be reported")
+	assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", recorder.Header().Get("Cache-Control"))
+	assert.Equal(t, "id: 1\nevent: greeting\nretry: 5000\ndata: hello\ndata: world\n\ndata: {\"count\":2}\n\n", recorder.Body.String())
+}
+
+func TestHTTPResponderWithServerSentEventsKeepAlive(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithServerSentEvents(recorder, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), make(chan core.ServerSentEvent), 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, recorder.Body.String(), ": keep-alive\n\n")
+}
+
+func TestHTTPResponderWithServerSentEventsShouldNotInjectFields(t *testing.T) {
+	events := make(chan core.ServerSentEvent, 1)
+	events <- core.ServerSentEvent{ID: "1\revent: admin\r", Event: "greeting\r\ndata: forged", Data: "hello\rdata: world\r\n!"}
+	close(events)
+	recorder := httptest.NewRecorder()
+	err := core.RespondWithServerSentEvents(recorder, httptest.NewRequest(http.MethodGet, "/", nil), events, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "id: 1event: admin\nevent: greetingdata: forged\ndata: hello\ndata: data: world\ndata: !\n\n", recorder.Body.String())
+}
+
+// writesRecorder is a http.ResponseWriter that hands its writes over a channel
+type writesRecorder struct {
+	header http.Header
+	writes chan string
+}
+
+func (recorder *writesRecorder) Header() http.Header { return recorder.header }
+func (recorder *writesRecorder) WriteHeader(int)     {}
+func (recorder *writesRecorder) Write(payload []byte) (int, error) {
+	recorder.writes <- string(payload)
+	return len(payload), nil
+}
+
+func TestHTTPResponderWithServerSentEventsKeepAliveWithClock(t *testing.T) {
+	clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	recorder := &writesRecorder{header: http.Header{}, writes: make(chan string)}
+	events := make(chan core.ServerSentEvent)
+	done := make(chan error)
+	go func() {
+		done <- core.RespondWithServerSentEventsWithClock(clock, recorder, httptest.NewRequest(http.MethodGet, "/", nil), events, 15*time.Second)
+	}()
+
+	var written string
+	require.Eventually(t, func() bool {
+		clock.Advance(15 * time.Second)
+		select {
+		case written = <-recorder.writes:
+			return true
+		case <-time.After(5 * time.Millisecond):
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, ": keep-alive\n\n", written)
+
+	go func() { events <- core.ServerSentEvent{Data: "hello"} }()
+	for written == ": keep-alive\n\n" { // ticks can still be pending
+		written = <-recorder.writes
+	}
+	assert.Equal(t, "data: hello\n\n", written)
+	close(events)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 0, clock.ActiveTickers(), "The keep-alive ticker should be stopped")
+}
+
+func TestHTTPResponderWithServerSentEventsShouldNotKeepAliveWhileEventsAreSent(t *testing.T) {
+	clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	recorder := &writesRecorder{header: http.Header{}, writes: make(chan string)}
+	events := make(chan core.ServerSentEvent)
+	done := make(chan error)
+	go func() {
+		done <- core.RespondWithServerSentEventsWithClock(clock, recorder, httptest.NewRequest(http.MethodGet, "/", nil), events, 15*time.Second)
+	}()
+	send := func(data string) {
+		go func() { events <- core.ServerSentEvent{Data: data} }()
+		assert.Equal(t, "data: "+data+"\n\n", <-recorder.writes)
+	}
+
+	send("hello")
+	clock.Advance(10 * time.Second)
+	send("world") // just before the keep-alive
+	send("again") // the stream has handled the previous event once this one is received
+	clock.Advance(10 * time.Second)
+	select {
+	case written := <-recorder.writes:
+		assert.Failf(t, "no keep-alive should be sent", "wrote %q", written)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(15 * time.Second)
+	assert.Equal(t, ": keep-alive\n\n", <-recorder.writes)
+	close(events)
+	assert.NoError(t, <-done)
+}
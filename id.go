@@ -0,0 +1,171 @@
+package core
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Prefix describes the prefix of a typed identifier
+//
+// Example:
+//
+//	type UserPrefix struct{}
+//
+//	func (UserPrefix) Prefix() string { return "usr" }
+//
+//	type UserID = core.ID[UserPrefix]
+type Prefix interface {
+	Prefix() string
+}
+
+// ID is a typed identifier that renders as its prefix, an underscore and its compact UUID (e.g. "usr_uTTgLJbLTeO6fDt9r1kxMQ")
+//
+// IDs of different prefixes cannot be mixed up, the compiler refuses to assign one to another
+// and the parsing refuses the wrong prefix.
+//
+// ID marshals as text (and therefore as JSON) in its prefixed form,
+// it is stored in SQL databases as a standard UUID string.
+type ID[P Prefix] struct {
+	UUID
+}
+
+// IDPrefixError is returned when parsing an ID with the wrong prefix
+type IDPrefixError struct {
+	Expected string
+	Value    string
+}
+
+// prefixedIdentifier describes identifiers that render with their prefix, like ID
+type prefixedIdentifier interface {
+	Identifiable
+	fmt.Stringer
+	Prefix() string
+}
+
+// NewID returns a new random ID
+func NewID[P Prefix]() ID[P] {
+	return ID[P]{NewUUID()}
+}
+
+// ParseID parses an ID in its prefixed form
+//
+// The UUID part can be in its compact or standard form. An IDPrefixError is returned if the prefix is not the one of P.
+func ParseID[P Prefix](value string) (ID[P], error) {
+	var id ID[P]
+	if err := id.parse(strings.TrimSpace(value)); err != nil {
+		return ID[P]{}, err
+	}
+	return id, nil
+}
+
+// Prefix returns the prefix of the ID
+func (id ID[P]) Prefix() string {
+	var prefix P
+	return prefix.Prefix()
+}
+
+// String returns the ID in its prefixed form
+//
+// If the ID is zero, an empty string is returned.
+//
+// implements fmt.Stringer
+func (id ID[P]) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	if len(id.Prefix()) == 0 {
+		return EncodeUUID(uuid.UUID(id.UUID))
+	}
+	return id.Prefix() + "_" + EncodeUUID(uuid.UUID(id.UUID))
+}
+
+// MarshalText marshals the ID in its prefixed form
+//
+// implements encoding.TextMarshaler
+func (id ID[P]) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText unmarshals the ID from its prefixed form
+//
+// If the text is empty, the ID is set to zero.
+//
+// implements encoding.TextUnmarshaler
+func (id *ID[P]) UnmarshalText(payload []byte) error {
+	if len(payload) == 0 {
+		*id = ID[P]{}
+		return nil
+	}
+	return id.parse(string(payload))
+}
+
+// Value returns the ID as a standard UUID string for SQL databases
+//
+// A zero ID is stored as NULL.
+//
+// implements driver.Valuer
+func (id ID[P]) Value() (driver.Value, error) {
+	if id.IsZero() {
+		return nil, nil
+	}
+	return uuid.UUID(id.UUID).String(), nil
+}
+
+// Scan reads the ID from a SQL database
+//
+// The source can be a standard or compact UUID, a prefixed ID or 16 raw bytes.
+//
+// implements sql.Scanner
+func (id *ID[P]) Scan(source any) error {
+	switch value := source.(type) {
+	case nil:
+		*id = ID[P]{}
+		return nil
+	case []byte:
+		if len(value) == 16 {
+			copy(id.UUID[:], value)
+			return nil
+		}
+		return id.Scan(string(value))
+	case string:
+		if prefix := id.Prefix(); len(prefix) > 0 && strings.HasPrefix(value, prefix+"_") {
+			return id.parse(value)
+		}
+		parsed, err := ParseUUID(value)
+		if err != nil {
+			if strings.Contains(value, "_") { // probably an ID with another prefix
+				return id.parse(value)
+			}
+			return err
+		}
+		id.UUID = parsed
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into an ID", source)
+}
+
+func (id *ID[P]) parse(value string) error {
+	prefix := id.Prefix()
+	if len(prefix) > 0 {
+		if !strings.HasPrefix(value, prefix+"_") {
+			return IDPrefixError{Expected: prefix, Value: value}
+		}
+		value = value[len(prefix)+1:]
+	}
+	parsed, err := ParseUUID(value)
+	if err != nil {
+		return err
+	}
+	id.UUID = parsed
+	return nil
+}
+
+// Error returns the string representation of this error
+//
+// implements error
+func (err IDPrefixError) Error() string {
+	return fmt.Sprintf(`invalid ID "%s", expected prefix "%s_"`, err.Value, err.Expected)
+}
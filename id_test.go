@@ -0,0 +1,133 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gildas/go-core"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type UserPrefix struct{}
+
+func (UserPrefix) Prefix() string { return "usr" }
+
+type OrderPrefix struct{}
+
+func (OrderPrefix) Prefix() string { return "ord" }
+
+type UserID = core.ID[UserPrefix]
+
+type User struct {
+	ID   UserID `json:"id"`
+	Name string `json:"name"`
+}
+
+func (user User) GetID() UserID {
+	return user.ID
+}
+
+func TestCanCreateID(t *testing.T) {
+	id := core.NewID[UserPrefix]()
+	assert.False(t, id.IsZero())
+	assert.Equal(t, "usr", id.Prefix())
+	assert.Regexp(t, `^usr_[A-Za-z0-9_-]{22}$`, id.String())
+
+	parsed, err := core.ParseID[UserPrefix](id.String())
+	require.NoError(t, err, "Failed to parse ID")
+	assert.Equal(t, id, parsed)
+
+	parsed, err = core.ParseID[UserPrefix]("usr_" + uuid.UUID(id.UUID).String())
+	require.NoError(t, err, "Failed to parse ID with a standard UUID")
+	assert.Equal(t, id, parsed)
+
+	var identifiable core.Identifiable = id
+	assert.Equal(t, uuid.UUID(id.UUID), identifiable.GetID())
+	assert.Empty(t, UserID{}.String())
+	assert.True(t, UserID{}.IsZero())
+}
+
+func TestShouldFailParsingIDWithWrongPrefix(t *testing.T) {
+	id := core.NewID[OrderPrefix]()
+	_, err := core.ParseID[UserPrefix](id.String())
+	require.Error(t, err, "Should have failed to parse an order ID as a user ID")
+	var prefixError core.IDPrefixError
+	require.ErrorAs(t, err, &prefixError)
+	assert.Equal(t, "usr", prefixError.Expected)
+
+	_, err = core.ParseID[UserPrefix]("usr_hello")
+	assert.Error(t, err, "Should have failed to parse an invalid UUID")
+
+	_, err = core.ParseID[UserPrefix](core.EncodeUUID(uuid.New()))
+	assert.ErrorAs(t, err, &core.IDPrefixError{})
+}
+
+func TestCanMarshalID(t *testing.T) {
+	user := User{ID: core.NewID[UserPrefix](), Name: "John"}
+	payload, err := json.Marshal(user)
+	require.NoError(t, err, "Failed to marshal user")
+	assert.JSONEq(t, `{"id":"`+user.ID.String()+`","name":"John"}`, string(payload))
+
+	var unmarshaled User
+	require.NoError(t, json.Unmarshal(payload, &unmarshaled), "Failed to unmarshal user")
+	assert.Equal(t, user, unmarshaled)
+
+	err = json.Unmarshal([]byte(`{"id":"ord_`+core.EncodeUUID(uuid.New())+`"}`), &unmarshaled)
+	assert.ErrorAs(t, err, &core.IDPrefixError{})
+
+	require.NoError(t, json.Unmarshal([]byte(`{"id":""}`), &unmarshaled))
+	assert.True(t, unmarshaled.ID.IsZero())
+}
+
+func TestCanStoreIDInSQL(t *testing.T) {
+	id := core.NewID[UserPrefix]()
+	value, err := id.Value()
+	require.NoError(t, err, "Failed to get SQL value")
+	assert.Equal(t, uuid.UUID(id.UUID).String(), value)
+
+	value, err = UserID{}.Value()
+	require.NoError(t, err, "Failed to get SQL value")
+	assert.Nil(t, value)
+
+	sources := []any{uuid.UUID(id.UUID).String(), id.String(), []byte(uuid.UUID(id.UUID).String()), id.UUID[:]}
+	for _, source := range sources {
+		var scanned UserID
+		require.NoErrorf(t, scanned.Scan(source), "Failed to scan %v", source)
+		assert.Equal(t, id, scanned)
+	}
+
+	var scanned UserID
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsZero())
+	assert.Error(t, scanned.Scan(12))
+	assert.ErrorAs(t, scanned.Scan(core.NewID[OrderPrefix]().String()), &core.IDPrefixError{})
+}
+
+func TestCanScanCompactIDWithUnderscore(t *testing.T) {
+	compact := "IXgfYZAP00ULzoAKAq_UiX" // "_" is part of the compact alphabet
+	expected, err := core.ParseUUID(compact)
+	require.NoError(t, err, "Failed to parse compact UUID")
+
+	for _, source := range []any{compact, "usr_" + compact, []byte(compact)} {
+		var scanned UserID
+		require.NoErrorf(t, scanned.Scan(source), "Failed to scan %v", source)
+		assert.Equal(t, expected, scanned.UUID)
+	}
+}
+
+func TestCanDecorateAndReferenceID(t *testing.T) {
+	user := User{ID: core.NewID[UserPrefix](), Name: "John"}
+
+	decorated := core.Decorate(user, "/api/v1")
+	assert.Equal(t, "/api/v1/users/"+user.ID.String(), decorated.SelfURI)
+
+	reference, err := json.Marshal(core.GetReference(user))
+	require.NoError(t, err, "Failed to marshal reference")
+	assert.JSONEq(t, `{"id":"`+user.ID.String()+`"}`, string(reference))
+
+	reference, err = json.Marshal(core.GetReference(user.ID))
+	require.NoError(t, err, "Failed to marshal reference")
+	assert.JSONEq(t, `{"id":"`+user.ID.String()+`"}`, string(reference))
+}
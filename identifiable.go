@@ -1,6 +1,10 @@
 package core
 
-import "github.com/google/uuid"
+import (
+	"reflect"
+
+	"github.com/google/uuid"
+)
 
 // Identifiable describes that can get their Identifier as a UUID
 type Identifiable interface {
@@ -35,3 +39,30 @@ func MatchStringIdentifiable[T StringIdentifiable](search T) func(T) bool {
 		return item.GetID() == search.GetID()
 	}
 }
+
+// getIdentifier gets the identifier of an Identifiable or StringIdentifiable as a string
+//
+// Typed IDs are rendered in their prefixed form, even when they are returned by the GetID method of the element.
+// A nil element, or a nil pointer, has no identifier.
+func getIdentifier(element any) (string, bool) {
+	if element == nil {
+		return "", false
+	}
+	if value := reflect.ValueOf(element); value.Kind() == reflect.Pointer && value.IsNil() {
+		return "", false
+	}
+	switch actual := element.(type) {
+	case prefixedIdentifier:
+		return actual.String(), true
+	case Identifiable:
+		return actual.GetID().String(), true
+	case StringIdentifiable:
+		return actual.GetID(), true
+	}
+	if method := reflect.ValueOf(element).MethodByName("GetID"); method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+		if identifier, ok := method.Call(nil)[0].Interface().(prefixedIdentifier); ok {
+			return identifier.String(), true
+		}
+	}
+	return "", false
+}
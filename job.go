@@ -0,0 +1,291 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// JobFunc is the function run by a Job
+//
+// tick is the number of the run, starting at 0, and at is the time the run was scheduled or triggered.
+// The context is cancelled when the Job is stopped.
+type JobFunc func(ctx context.Context, tick int64, at time.Time) error
+
+// OverlapPolicy tells what a Job does when a run is due while another run is in flight
+type OverlapPolicy int
+
+const (
+	// OverlapPolicySkip drops the runs that are due while another run is in flight
+	OverlapPolicySkip OverlapPolicy = iota
+	// OverlapPolicyQueue runs once more after the run in flight, the runs that are due in the meantime are coalesced
+	OverlapPolicyQueue
+	// OverlapPolicyConcurrent runs concurrently with the run in flight
+	OverlapPolicyConcurrent
+)
+
+// Job runs a JobFunc regularly in its own go routine
+//
+// A Job is created with NewJob, configured through its fields, and started with Start.
+// Errors returned by the JobFunc, and its panics as JobPanicError, are given to OnError.
+// If OnError is nil, they are logged in the default slog.Logger.
+//
+// Example:
+//
+//	job := core.NewJob(5*time.Minute, func(ctx context.Context, tick int64, at time.Time) error {
+//		return store.Purge(ctx)
+//	})
+//	job.Immediate = true
+//	if err := job.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer job.Stop()
+type Job struct {
+	// Overlap tells what to do when a run is due while another run is in flight
+	Overlap OverlapPolicy
+
+	// Immediate runs the JobFunc as soon as the Job starts, instead of waiting for the first interval
+	Immediate bool
+
+	// Clock is used for the ticks of the Job, the RealClock is used if nil
+	Clock Clock
+
+	// OnError is called with the errors of the runs
+	OnError func(err error)
+
+	run      JobFunc
+	interval time.Duration
+	trigger  chan struct{}
+	reset    chan struct{}
+	done     chan struct{}
+	cancel   context.CancelFunc
+	started  bool
+	stopped  bool
+	mutex    sync.Mutex
+}
+
+// JobPanicError is given to Job.OnError when a JobFunc panics
+type JobPanicError struct {
+	Value any
+	Stack []byte
+}
+
+var (
+	// ErrJobStarted is returned when a Job is started more than once
+	ErrJobStarted = errors.New("job already started")
+	// ErrJobStopped is returned when a Job is started after it was stopped
+	ErrJobStopped = errors.New("job already stopped")
+)
+
+// triggeredRunKey marks the context of the runs started by Trigger
+type triggeredRunKey struct{}
+
+// NewJob creates a new Job that runs the given JobFunc at every interval
+func NewJob(interval time.Duration, run JobFunc) *Job {
+	return &Job{
+		run:      run,
+		interval: interval,
+		trigger:  make(chan struct{}, 1),
+		reset:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts the Job
+//
+// The Job runs until the context is cancelled or Stop is called. A Job can be started only once, ErrJobStarted is returned otherwise.
+// A Job that was stopped cannot be started, ErrJobStopped is returned.
+func (job *Job) Start(ctx context.Context) error {
+	if job.interval <= 0 {
+		return fmt.Errorf("non-positive interval for Job: %s", job.interval)
+	}
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	if job.stopped {
+		return ErrJobStopped
+	}
+	if job.started {
+		return ErrJobStarted
+	}
+	job.started = true
+	if job.Clock == nil {
+		job.Clock = RealClock{}
+	}
+	ctx, job.cancel = context.WithCancel(ctx)
+	go job.loop(ctx, job.Clock.NewTicker(job.interval))
+	return nil
+}
+
+// Stop stops the Job and waits for the runs in flight to finish
+//
+// The context given to the runs is cancelled. Stop must not be called from the JobFunc, as it would wait for itself.
+// Stopping a Job that was not started closes its Done channel, the Job cannot be started afterwards.
+func (job *Job) Stop() {
+	job.mutex.Lock()
+	cancel, started := job.cancel, job.started
+	if !started && !job.stopped {
+		close(job.done)
+	}
+	job.stopped = true
+	job.mutex.Unlock()
+	if !started {
+		return
+	}
+	cancel()
+	<-job.done
+}
+
+// Trigger runs the Job now, without changing its schedule
+//
+// Trigger does not block, triggers that happen before the Job handles the previous one are coalesced.
+// The Overlap policy applies if a run is in flight.
+func (job *Job) Trigger() {
+	select {
+	case job.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Reset changes the interval of the Job, the next run is due after the new interval
+//
+// Reset does not block, so it can be called from the JobFunc.
+func (job *Job) Reset(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	job.mutex.Lock()
+	job.interval = interval
+	job.mutex.Unlock()
+	select {
+	case job.reset <- struct{}{}:
+	default:
+	}
+}
+
+// Interval returns the current interval of the Job
+func (job *Job) Interval() time.Duration {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	return job.interval
+}
+
+// Done returns a channel that is closed when the Job is stopped and its runs are finished
+//
+// The channel is not closed until Stop is called or the context given to Start is done.
+func (job *Job) Done() <-chan struct{} {
+	return job.done
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err JobPanicError) Error() string {
+	return fmt.Sprintf("job panicked: %v", err.Value)
+}
+
+// Unwrap returns the error the JobFunc panicked with, if any
+func (err JobPanicError) Unwrap() error {
+	if inner, ok := err.Value.(error); ok {
+		return inner
+	}
+	return nil
+}
+
+func (job *Job) loop(ctx context.Context, ticker Ticker) {
+	defer close(job.done)
+	defer ticker.Stop()
+	clock := job.Clock
+
+	var (
+		tick            int64
+		running         int
+		pending         bool
+		pendedAt        time.Time
+		pendedTriggered bool
+		finished        = make(chan struct{})
+	)
+	start := func(at time.Time, triggered bool) {
+		running++
+		runCtx := ctx
+		if triggered {
+			runCtx = context.WithValue(ctx, triggeredRunKey{}, true)
+		}
+		go job.execute(runCtx, tick, at, finished)
+		tick++
+	}
+	dispatch := func(at time.Time, triggered bool) {
+		if running > 0 {
+			switch job.Overlap {
+			case OverlapPolicySkip:
+				return
+			case OverlapPolicyQueue:
+				if !pending {
+					pending, pendedAt, pendedTriggered = true, at, triggered
+				}
+				return
+			}
+		}
+		start(at, triggered)
+	}
+
+	if job.Immediate {
+		start(clock.Now(), false)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			for ; running > 0; running-- {
+				<-finished
+			}
+			return
+		case at := <-ticker.C():
+			dispatch(at, false)
+		case <-job.trigger:
+			dispatch(clock.Now(), true)
+		case <-job.reset:
+			ticker.Reset(job.Interval())
+		case <-finished:
+			running--
+			if pending && running == 0 {
+				pending = false
+				start(pendedAt, pendedTriggered)
+			}
+		}
+	}
+}
+
+// isTriggeredRun tells if the run of the given context was started by Trigger
+func isTriggeredRun(ctx context.Context) bool {
+	triggered, _ := ctx.Value(triggeredRunKey{}).(bool)
+	return triggered
+}
+
+// execute runs the JobFunc once
+func (job *Job) execute(ctx context.Context, tick int64, at time.Time, finished chan<- struct{}) {
+	defer func() { finished <- struct{}{} }()
+	if err := runJobFunc(ctx, job.run, tick, at); err != nil {
+		job.report(ctx, err)
+	}
+}
+
+func (job *Job) report(ctx context.Context, err error) {
+	if job.OnError != nil {
+		job.OnError(err)
+		return
+	}
+	slog.ErrorContext(ctx, "Job failed", "error", err)
+}
+
+// runJobFunc runs a JobFunc, its panics are returned as JobPanicError
+func runJobFunc(ctx context.Context, run JobFunc, tick int64, at time.Time) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = JobPanicError{Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+	return run(ctx, tick, at)
+}
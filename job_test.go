@@ -0,0 +1,180 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForRun(t *testing.T, runs <-chan int64) int64 {
+	t.Helper()
+	select {
+	case tick := <-runs:
+		return tick
+	case <-time.After(time.Second):
+		require.FailNow(t, "the job did not run")
+		return -1
+	}
+}
+
+func assertNoRun(t *testing.T, runs <-chan int64) {
+	t.Helper()
+	select {
+	case tick := <-runs:
+		assert.Failf(t, "the job should not have run", "tick #%d", tick)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCanRunJob(t *testing.T) {
+	clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	runs := make(chan int64, 10)
+	job := core.NewJob(time.Minute, func(ctx context.Context, tick int64, at time.Time) error {
+		runs <- tick
+		return nil
+	})
+	job.Clock = clock
+	job.Immediate = true
+	require.NoError(t, job.Start(context.Background()))
+	defer job.Stop()
+
+	assert.Equal(t, int64(0), waitForRun(t, runs))
+	clock.Advance(time.Minute)
+	assert.Equal(t, int64(1), waitForRun(t, runs))
+
+	job.Trigger()
+	assert.Equal(t, int64(2), waitForRun(t, runs))
+
+	job.Reset(time.Hour)
+	assert.Eventually(t, func() bool { return job.Interval() == time.Hour }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond) // let the job reset its ticker
+	clock.Advance(time.Minute)
+	assertNoRun(t, runs)
+	clock.Advance(time.Hour)
+	assert.Equal(t, int64(3), waitForRun(t, runs))
+
+	assert.ErrorIs(t, job.Start(context.Background()), core.ErrJobStarted)
+}
+
+func TestCanStopJob(t *testing.T) {
+	started := make(chan struct{})
+	var finished atomic.Bool
+	job := core.NewJob(time.Hour, func(ctx context.Context, tick int64, at time.Time) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(true)
+		return ctx.Err()
+	})
+	job.Immediate = true
+	job.OnError = func(err error) {}
+	require.NoError(t, job.Start(context.Background()))
+	<-started
+
+	job.Stop()
+	assert.True(t, finished.Load(), "Stop should wait for the run in flight")
+	select {
+	case <-job.Done():
+	default:
+		assert.Fail(t, "the done channel should be closed")
+	}
+	job.Stop() // stopping twice is fine
+
+	core.NewJob(time.Hour, nil).Stop() // stopping a job that was not started is fine
+}
+
+func TestShouldCloseDoneWhenStoppingJobThatWasNotStarted(t *testing.T) {
+	job := core.NewJob(time.Hour, func(ctx context.Context, tick int64, at time.Time) error { return nil })
+	job.Stop()
+	job.Stop() // stopping twice is fine
+	select {
+	case <-job.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "the job should be done")
+	}
+	assert.ErrorIs(t, job.Start(context.Background()), core.ErrJobStopped)
+}
+
+func TestCanStopJobWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := core.NewJob(time.Hour, func(ctx context.Context, tick int64, at time.Time) error { return nil })
+	require.NoError(t, job.Start(ctx))
+	cancel()
+	select {
+	case <-job.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "the job should have stopped")
+	}
+}
+
+func TestJobOverlapPolicies(t *testing.T) {
+	policies := map[core.OverlapPolicy]int{
+		core.OverlapPolicySkip:       1,
+		core.OverlapPolicyQueue:      2,
+		core.OverlapPolicyConcurrent: 3,
+	}
+	for policy, expected := range policies {
+		clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+		release := make(chan struct{})
+		runs := make(chan int64, 10)
+		job := core.NewJob(time.Minute, func(ctx context.Context, tick int64, at time.Time) error {
+			runs <- tick
+			<-release
+			return nil
+		})
+		job.Clock = clock
+		job.Overlap = policy
+		job.Immediate = true
+		require.NoError(t, job.Start(context.Background()))
+
+		waitForRun(t, runs)
+		for i := 0; i < 2; i++ {
+			clock.Advance(time.Minute)
+			time.Sleep(10 * time.Millisecond) // let the job handle the tick
+		}
+		close(release)
+		count := 1
+		for count < expected {
+			waitForRun(t, runs)
+			count++
+		}
+		assertNoRun(t, runs)
+		job.Stop()
+		assert.Equal(t, expected, count, "policy %d", policy)
+	}
+}
+
+func TestJobShouldCapturePanicsAndErrors(t *testing.T) {
+	errs := make(chan error, 2)
+	job := core.NewJob(time.Hour, func(ctx context.Context, tick int64, at time.Time) error {
+		if tick == 0 {
+			panic(errors.ErrUnsupported)
+		}
+		return errors.New("failed")
+	})
+	job.Immediate = true
+	job.OnError = func(err error) { errs <- err }
+	require.NoError(t, job.Start(context.Background()))
+	defer job.Stop()
+
+	err := <-errs
+	var panicError core.JobPanicError
+	require.ErrorAs(t, err, &panicError)
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+	assert.NotEmpty(t, panicError.Stack)
+	assert.Equal(t, "job panicked: unsupported operation", err.Error())
+
+	job.Trigger()
+	assert.EqualError(t, <-errs, "failed")
+}
+
+func TestShouldFailStartingJobWithInvalidInterval(t *testing.T) {
+	job := core.NewJob(0, func(ctx context.Context, tick int64, at time.Time) error { return nil })
+	assert.Error(t, job.Start(context.Background()))
+}
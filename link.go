@@ -0,0 +1,139 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Link is a hypermedia link of a DecoratedResource
+//
+// If Templated is true, Href is an RFC 6570 URI Template (e.g.: "/users{?name,limit}"), see Expand.
+type Link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// uriTemplateOperator describes how an RFC 6570 expression is expanded
+type uriTemplateOperator struct {
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var uriTemplateOperators = map[byte]uriTemplateOperator{
+	'+': {first: "", sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// Expand expands the URI Template of a templated Link with the given values
+//
+// The expressions of RFC 6570 up to level 3 are supported ({var}, {+var}, {#var}, {.var}, {/var}, {;var}, {?var}, {&var},
+// with one or more variables). Slices are expanded as comma-separated lists, other values are formatted with fmt.Sprint.
+// Variables without a value are omitted.
+//
+// If the Link is not templated, its Href is returned as is.
+func (link Link) Expand(values map[string]any) string {
+	if !link.Templated {
+		return link.Href
+	}
+	return ExpandURITemplate(link.Href, values)
+}
+
+// ExpandURITemplate expands an RFC 6570 URI Template with the given values
+//
+// See Link.Expand
+func ExpandURITemplate(template string, values map[string]any) string {
+	var builder strings.Builder
+	for {
+		start := strings.Index(template, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(template[start:], "}")
+		if end < 0 {
+			break
+		}
+		builder.WriteString(template[:start])
+		builder.WriteString(expandURITemplateExpression(template[start+1:start+end], values))
+		template = template[start+end+1:]
+	}
+	builder.WriteString(template)
+	return builder.String()
+}
+
+func expandURITemplateExpression(expression string, values map[string]any) string {
+	operator := uriTemplateOperator{sep: ","}
+	if len(expression) > 0 {
+		if found, ok := uriTemplateOperators[expression[0]]; ok {
+			operator = found
+			expression = expression[1:]
+		}
+	}
+	expanded := []string{}
+	for _, name := range strings.Split(expression, ",") {
+		items, ok := uriTemplateValue(values[name])
+		if !ok {
+			continue
+		}
+		for i, item := range items {
+			items[i] = encodeURITemplateValue(item, operator.reserved)
+		}
+		value := strings.Join(items, ",")
+		switch {
+		case !operator.named:
+			expanded = append(expanded, value)
+		case len(value) == 0:
+			expanded = append(expanded, name+operator.ifEmpty)
+		default:
+			expanded = append(expanded, name+"="+value)
+		}
+	}
+	if len(expanded) == 0 {
+		return ""
+	}
+	return operator.first + strings.Join(expanded, operator.sep)
+}
+
+// uriTemplateValue formats a value for a URI Template, slices give one item per element
+func uriTemplateValue(value any) ([]string, bool) {
+	if value == nil {
+		return nil, false
+	}
+	list := reflect.ValueOf(value)
+	if list.Kind() == reflect.Slice || list.Kind() == reflect.Array {
+		if list.Len() == 0 {
+			return nil, false
+		}
+		items := make([]string, list.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(list.Index(i).Interface())
+		}
+		return items, true
+	}
+	return []string{fmt.Sprint(value)}, true
+}
+
+// encodeURITemplateValue percent-encodes the characters that are not allowed in an expansion
+func encodeURITemplateValue(value string, reserved bool) string {
+	const unreserved = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-._~"
+	const reservedCharacters = ":/?#[]@!$&'()*+,;=%"
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if strings.IndexByte(unreserved, b) >= 0 || (reserved && strings.IndexByte(reservedCharacters, b) >= 0) {
+			builder.WriteByte(b)
+		} else {
+			_, _ = fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
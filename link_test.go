@@ -0,0 +1,52 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanExpandURITemplates(t *testing.T) {
+	values := map[string]any{
+		"var":   "value",
+		"hello": "Hello World!",
+		"path":  "/foo/bar",
+		"x":     1024,
+		"y":     768,
+		"empty": "",
+		"list":  []string{"red", "green", "blue"},
+	}
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"{#path}", "#/foo/bar"},
+		{"map?{x,y}", "map?1024,768"},
+		{"{.var}", ".value"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"/users{?undefined}", "/users"},
+		{"{list}", "red,green,blue"},
+		{"{?list}", "?list=red,green,blue"},
+	}
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			assert.Equal(t, test.expected, core.ExpandURITemplate(test.template, values))
+		})
+	}
+}
+
+func TestCanExpandLinks(t *testing.T) {
+	link := core.Link{Href: "/api/v1/users{?name,limit}", Templated: true}
+	assert.Equal(t, "/api/v1/users?name=john&limit=10", link.Expand(map[string]any{"name": "john", "limit": 10}))
+
+	link = core.Link{Href: "/api/v1/users{?name}"}
+	assert.Equal(t, "/api/v1/users{?name}", link.Expand(map[string]any{"name": "john"}), "Links that are not templated should not be expanded")
+}
@@ -0,0 +1,243 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Page is a page of items of a collection
+//
+// Next and Prev are opaque cursors to the next and previous pages, they are empty on the last and first pages.
+// Pages of decorated resources are built with DecoratePage.
+type Page[T any] struct {
+	Items   []T    `json:"items"`
+	Total   int    `json:"total"`
+	Next    string `json:"next,omitempty"`
+	Prev    string `json:"prev,omitempty"`
+	SelfURI string `json:"selfURI,omitempty"`
+}
+
+// PageRequest describes the page a client asks for
+//
+// Clients give either an offset or a cursor received in a previous Page.
+type PageRequest struct {
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+	Cursor string `query:"cursor"`
+}
+
+// CursorCodec encodes and decodes opaque cursors
+//
+// Cursors are JSON values signed with HMAC-SHA256 and encoded in base64url, like EncodeUUID does.
+// Clients cannot forge cursors without the Key, the zero value can only be used for cursors that do not need to be protected.
+type CursorCodec struct {
+	Key []byte
+}
+
+// Paginator paginates in-memory collections
+//
+// If Filter is not nil, only the items it accepts are paginated.
+// If Sort is not nil, the items are sorted before being paginated (the given slice is not modified).
+type Paginator[T any] struct {
+	Codec  CursorCodec
+	Filter func(item T) bool
+	Sort   func(a, b T) bool
+}
+
+// InvalidCursorError is returned when a cursor cannot be decoded or was not signed with the expected key
+type InvalidCursorError struct {
+	Cursor string
+}
+
+var (
+	// DefaultPageLimit is the Limit used by ParsePageRequest when the request does not give one
+	DefaultPageLimit = 20
+
+	// MaxPageLimit is the maximum Limit returned by ParsePageRequest
+	MaxPageLimit = 100
+)
+
+// cursorSignatureLength is the length of the truncated HMAC of cursors
+const cursorSignatureLength = 16
+
+// ParsePageRequest reads the limit, offset and cursor query parameters of a request
+//
+// The limit defaults to DefaultPageLimit and is capped to MaxPageLimit.
+// A RequestParametersError is returned if the parameters are not valid.
+func ParsePageRequest(r *http.Request) (PageRequest, error) {
+	var request PageRequest
+	if err := BindRequest(r, &request); err != nil {
+		return PageRequest{}, err
+	}
+	if request.Offset < 0 {
+		return PageRequest{}, RequestParametersError{Errors: []RequestParameterError{{
+			In:    "query",
+			Name:  "offset",
+			Value: r.URL.Query().Get("offset"),
+			Cause: fmt.Errorf("offset must not be negative"),
+		}}}
+	}
+	if request.Limit <= 0 {
+		request.Limit = DefaultPageLimit
+	}
+	if request.Limit > MaxPageLimit {
+		request.Limit = MaxPageLimit
+	}
+	return request, nil
+}
+
+// Encode encodes a value into an opaque cursor
+func (codec CursorCodec) Encode(value any) (string, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(append(payload, codec.sign(payload)...)), nil
+}
+
+// Decode decodes an opaque cursor into a value
+//
+// An InvalidCursorError is returned if the cursor is not valid or was not signed with the Key of this CursorCodec.
+func (codec CursorCodec) Decode(cursor string, value any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) <= cursorSignatureLength {
+		return InvalidCursorError{Cursor: cursor}
+	}
+	payload, signature := raw[:len(raw)-cursorSignatureLength], raw[len(raw)-cursorSignatureLength:]
+	if !hmac.Equal(signature, codec.sign(payload)) {
+		return InvalidCursorError{Cursor: cursor}
+	}
+	if err := json.Unmarshal(payload, value); err != nil {
+		return InvalidCursorError{Cursor: cursor}
+	}
+	return nil
+}
+
+func (codec CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, codec.Key)
+	mac.Write(payload)
+	return mac.Sum(nil)[:cursorSignatureLength]
+}
+
+// offsetCursor is the content of the cursors of a Paginator
+type offsetCursor struct {
+	Offset int `json:"o"`
+}
+
+// Paginate returns the page of the items described by the request
+//
+// If the request has a cursor, it takes precedence over its offset.
+// An InvalidCursorError is returned if the cursor is not valid or has a negative offset,
+// a RequestParametersError is returned if the offset of the request is negative.
+// An offset beyond the items gives an empty page.
+func (paginator Paginator[T]) Paginate(items []T, request PageRequest) (Page[T], error) {
+	offset := request.Offset
+	if len(request.Cursor) > 0 {
+		var cursor offsetCursor
+		if err := paginator.Codec.Decode(request.Cursor, &cursor); err != nil {
+			return Page[T]{}, err
+		}
+		if cursor.Offset < 0 {
+			return Page[T]{}, InvalidCursorError{Cursor: request.Cursor}
+		}
+		offset = cursor.Offset
+	} else if offset < 0 {
+		return Page[T]{}, RequestParametersError{Errors: []RequestParameterError{{
+			In:    "query",
+			Name:  "offset",
+			Value: strconv.Itoa(offset),
+			Cause: fmt.Errorf("offset must not be negative"),
+		}}}
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if paginator.Filter != nil {
+		items = Filter(items, paginator.Filter)
+	} else {
+		items = append([]T{}, items...)
+	}
+	if paginator.Sort != nil {
+		Sort(items, paginator.Sort)
+	}
+
+	offset = min(offset, len(items))
+	page := Page[T]{Items: []T{}, Total: len(items)}
+	if offset < len(items) {
+		page.Items = items[offset:min(offset+limit, len(items))]
+	}
+	if offset+limit < len(items) {
+		page.Next, _ = paginator.Codec.Encode(offsetCursor{Offset: offset + limit})
+	}
+	if offset > 0 {
+		page.Prev, _ = paginator.Codec.Encode(offsetCursor{Offset: max(0, offset-limit)})
+	}
+	return page, nil
+}
+
+// SetPageLinks sets the SelfURI of a page and adds its RFC 8288 Link header to the response
+//
+// The links are built from the URL of the request, with the cursor query parameter set to the Next and Prev cursors of the page.
+func SetPageLinks[T any](w http.ResponseWriter, r *http.Request, page *Page[T]) {
+	page.SelfURI = r.URL.RequestURI()
+	AddLinkHeader(w, "self", page.SelfURI)
+	if len(page.Next) > 0 {
+		AddLinkHeader(w, "next", cursorURI(r.URL, page.Next))
+	}
+	if len(page.Prev) > 0 {
+		AddLinkHeader(w, "prev", cursorURI(r.URL, page.Prev))
+	}
+}
+
+// AddLinkHeader adds an RFC 8288 link to the Link header of a response
+//
+// Example:
+//
+//	core.AddLinkHeader(w, "next", "/users?cursor=eyJvIjoyMH0")
+//	// Link: </users?cursor=eyJvIjoyMH0>; rel="next"
+func AddLinkHeader(w http.ResponseWriter, rel, uri string) {
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="%s"`, uri, rel))
+}
+
+// DecoratePage decorates all items of a page of identifiable items
+//
+// See DecorateAll
+func DecoratePage[T any](page Page[T], rootpath string) Page[DecoratedResource] {
+	return Page[DecoratedResource]{
+		Items:   DecorateAll(page.Items, rootpath),
+		Total:   page.Total,
+		Next:    page.Next,
+		Prev:    page.Prev,
+		SelfURI: page.SelfURI,
+	}
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err InvalidCursorError) Error() string {
+	return fmt.Sprintf(`Invalid cursor "%s"`, err.Cursor)
+}
+
+// HTTPStatus returns 400 Bad Request
+//
+// implements HTTPStatuser
+func (err InvalidCursorError) HTTPStatus() int {
+	return http.StatusBadRequest
+}
+
+func cursorURI(base *url.URL, cursor string) string {
+	uri := *base
+	query := uri.Query()
+	query.Del("offset")
+	query.Set("cursor", cursor)
+	uri.RawQuery = query.Encode()
+	return uri.RequestURI()
+}
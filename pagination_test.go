@@ -0,0 +1,171 @@
+package core_test
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanParsePageRequest(t *testing.T) {
+	request, err := core.ParsePageRequest(httptest.NewRequest(http.MethodGet, "/users", nil))
+	require.NoError(t, err)
+	assert.Equal(t, core.PageRequest{Limit: core.DefaultPageLimit}, request)
+
+	request, err = core.ParsePageRequest(httptest.NewRequest(http.MethodGet, "/users?limit=5&offset=10", nil))
+	require.NoError(t, err)
+	assert.Equal(t, core.PageRequest{Limit: 5, Offset: 10}, request)
+
+	request, err = core.ParsePageRequest(httptest.NewRequest(http.MethodGet, "/users?limit=1000&cursor=abc", nil))
+	require.NoError(t, err)
+	assert.Equal(t, core.PageRequest{Limit: core.MaxPageLimit, Cursor: "abc"}, request)
+}
+
+func TestShouldFailParsingInvalidPageRequest(t *testing.T) {
+	_, err := core.ParsePageRequest(httptest.NewRequest(http.MethodGet, "/users?limit=ten", nil))
+	assert.Equal(t, http.StatusBadRequest, core.ErrorStatus(err))
+
+	_, err = core.ParsePageRequest(httptest.NewRequest(http.MethodGet, "/users?offset=-1", nil))
+	var parametersError core.RequestParametersError
+	require.ErrorAs(t, err, &parametersError)
+	assert.Equal(t, "offset", parametersError.Errors[0].Name)
+}
+
+func TestCanEncodeAndDecodeCursors(t *testing.T) {
+	codec := core.CursorCodec{Key: []byte("secret")}
+	cursor, err := codec.Encode(map[string]any{"after": "john"})
+	require.NoError(t, err)
+	assert.NotContains(t, cursor, "=")
+	assert.NotContains(t, cursor, "+")
+	assert.NotContains(t, cursor, "/")
+
+	var value map[string]any
+	require.NoError(t, codec.Decode(cursor, &value))
+	assert.Equal(t, "john", value["after"])
+}
+
+func TestShouldFailDecodingTamperedCursors(t *testing.T) {
+	codec := core.CursorCodec{Key: []byte("secret")}
+	cursor, err := codec.Encode(map[string]any{"after": "john"})
+	require.NoError(t, err)
+
+	var value map[string]any
+	err = core.CursorCodec{Key: []byte("other")}.Decode(cursor, &value)
+	assert.ErrorAs(t, err, &core.InvalidCursorError{})
+	assert.Equal(t, http.StatusBadRequest, core.ErrorStatus(err))
+
+	tampered := []byte(cursor)
+	tampered[0] ^= 1
+	assert.Error(t, codec.Decode(string(tampered), &value))
+	assert.Error(t, codec.Decode("not a cursor!", &value))
+	assert.Error(t, codec.Decode("", &value))
+}
+
+func TestCanPaginate(t *testing.T) {
+	items := []int{9, 2, 7, 4, 5, 6, 3, 8, 1}
+	paginator := core.Paginator[int]{
+		Codec:  core.CursorCodec{Key: []byte("secret")},
+		Filter: func(item int) bool { return item != 9 },
+		Sort:   func(a, b int) bool { return a < b },
+	}
+
+	page, err := paginator.Paginate(items, core.PageRequest{Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, page.Items)
+	assert.Equal(t, 8, page.Total)
+	assert.Empty(t, page.Prev)
+	require.NotEmpty(t, page.Next)
+	assert.Equal(t, []int{9, 2, 7, 4, 5, 6, 3, 8, 1}, items, "The given slice should not be modified")
+
+	page, err = paginator.Paginate(items, core.PageRequest{Limit: 3, Cursor: page.Next})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5, 6}, page.Items)
+	require.NotEmpty(t, page.Prev)
+	require.NotEmpty(t, page.Next)
+
+	next := page.Next
+	page, err = paginator.Paginate(items, core.PageRequest{Limit: 3, Cursor: page.Prev})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, page.Items)
+
+	page, err = paginator.Paginate(items, core.PageRequest{Limit: 3, Cursor: next})
+	require.NoError(t, err)
+	assert.Equal(t, []int{7, 8}, page.Items)
+	assert.Empty(t, page.Next)
+	assert.NotEmpty(t, page.Prev)
+}
+
+func TestCanPaginateWithOffset(t *testing.T) {
+	page, err := core.Paginator[string]{}.Paginate([]string{"a", "b", "c"}, core.PageRequest{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, page.Items)
+
+	page, err = core.Paginator[string]{}.Paginate([]string{"a", "b", "c"}, core.PageRequest{Limit: 2, Offset: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, page.Items)
+	assert.Equal(t, 3, page.Total)
+}
+
+func TestShouldFailPaginatingWithInvalidCursor(t *testing.T) {
+	_, err := core.Paginator[string]{}.Paginate([]string{"a", "b", "c"}, core.PageRequest{Limit: 2, Cursor: "bogus"})
+	assert.ErrorAs(t, err, &core.InvalidCursorError{})
+}
+
+func TestShouldFailPaginatingWithNegativeOffset(t *testing.T) {
+	paginator := core.Paginator[string]{} // the zero CursorCodec has no key, so anyone can forge cursors
+	forged, err := core.CursorCodec{}.Encode(map[string]int{"o": -5})
+	require.NoError(t, err)
+	_, err = paginator.Paginate([]string{"a", "b", "c"}, core.PageRequest{Limit: 2, Cursor: forged})
+	assert.ErrorAs(t, err, &core.InvalidCursorError{})
+
+	_, err = paginator.Paginate([]string{"a", "b", "c"}, core.PageRequest{Limit: 2, Offset: -1})
+	var parametersError core.RequestParametersError
+	require.ErrorAs(t, err, &parametersError)
+	assert.Equal(t, "offset", parametersError.Errors[0].Name)
+
+	huge, err := core.CursorCodec{}.Encode(map[string]int{"o": math.MaxInt})
+	require.NoError(t, err)
+	page, err := paginator.Paginate([]string{"a", "b", "c"}, core.PageRequest{Limit: 2, Cursor: huge})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, page.Items)
+	assert.Empty(t, page.Next)
+	assert.NotEmpty(t, page.Prev)
+}
+
+func TestCanSetPageLinks(t *testing.T) {
+	paginator := core.Paginator[int]{Codec: core.CursorCodec{Key: []byte("secret")}}
+	page, err := paginator.Paginate([]int{1, 2, 3, 4, 5}, core.PageRequest{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	core.SetPageLinks(recorder, httptest.NewRequest(http.MethodGet, "/numbers?limit=2&offset=2", nil), &page)
+	assert.Equal(t, "/numbers?limit=2&offset=2", page.SelfURI)
+	assert.Equal(t, []string{
+		`</numbers?limit=2&offset=2>; rel="self"`,
+		`</numbers?cursor=` + page.Next + `&limit=2>; rel="next"`,
+		`</numbers?cursor=` + page.Prev + `&limit=2>; rel="prev"`,
+	}, recorder.Header().Values("Link"))
+}
+
+func TestCanDecoratePage(t *testing.T) {
+	items := []MockStringIdentifiable{{ID: "1"}, {ID: "2"}}
+	page, err := core.Paginator[MockStringIdentifiable]{}.Paginate(items, core.PageRequest{Limit: 1})
+	require.NoError(t, err)
+	page.SelfURI = "/api/v1/mockstringidentifiables?limit=1"
+
+	decorated := core.DecoratePage(page, "/api/v1")
+	payload, err := json.Marshal(decorated)
+	require.NoError(t, err)
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(payload, &result))
+	assert.Equal(t, float64(2), result["total"])
+	assert.Equal(t, "/api/v1/mockstringidentifiables?limit=1", result["selfURI"])
+	require.Len(t, result["items"], 1)
+	assert.Equal(t, "/api/v1/mockstringidentifiables/1", result["items"].([]any)[0].(map[string]any)["selfURI"])
+	assert.NotEmpty(t, result["next"])
+}
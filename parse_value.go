@@ -0,0 +1,126 @@
+package core
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeDurationType    = reflect.TypeOf(time.Duration(0))
+	coreDurationType    = reflect.TypeOf(Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	coreURLType         = reflect.TypeOf(URL{})
+	timeTimeType        = reflect.TypeOf(time.Time{})
+	coreTimeType        = reflect.TypeOf(Time{})
+	uuidType            = reflect.TypeOf(uuid.UUID{})
+	coreUUIDType        = reflect.TypeOf(UUID{})
+)
+
+// parseValue parses the given string and stores the result in value
+//
+// value must be settable. Slices are parsed from comma-separated strings,
+// pointers are allocated as needed. Times are parsed with ParseTime (so "yesterday" is valid),
+// UUIDs with ParseUUID (so the compact form is valid).
+func parseValue(value reflect.Value, raw string) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return parseValue(value.Elem(), raw)
+	}
+
+	switch value.Type() {
+	case timeDurationType, coreDurationType:
+		duration, err := ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	case urlType, coreURLType:
+		address, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(*address).Convert(value.Type()))
+		return nil
+	case timeTimeType, coreTimeType:
+		parsed, err := ParseTime(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(parsed).Convert(value.Type()))
+		return nil
+	case uuidType, coreUUIDType:
+		parsed, err := ParseUUID(raw)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(parsed).Convert(value.Type()))
+		return nil
+	}
+
+	if reflect.PointerTo(value.Type()).Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := parseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(raw), 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(strings.TrimSpace(raw), 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		items := strings.Split(raw, ",")
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := parseValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// parseBool parses a boolean the same way as GetEnvAsBool, but refuses unknown values
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "1", "on", "yes", "true":
+		return true, nil
+	case "0", "off", "no", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf(`"%s" is not a boolean`, raw)
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"path"
 )
 
 // GetReference gets a reference of an identifiable
@@ -10,6 +11,8 @@ import (
 //
 // If the element implements StringIdentifiable, the reference will use the string ID.
 //
+// If the element is an ID or its GetID method returns an ID, the reference will use the prefixed ID.
+//
 // If the element implements fmt.Stringer, the reference will use the string representation.
 //
 // Otherwise, the element itself is returned.
@@ -18,15 +21,38 @@ func GetReference(element any) any {
 		ID string `json:"id"`
 	}
 
-	switch actual := element.(type) {
-	case Identifiable:
-		ref.ID = actual.GetID().String()
-	case StringIdentifiable:
-		ref.ID = actual.GetID()
-	case fmt.Stringer:
-		ref.ID = actual.String()
-	default:
+	if id, ok := getIdentifier(element); ok {
+		ref.ID = id
+	} else if stringer, ok := element.(fmt.Stringer); ok {
+		ref.ID = stringer.String()
+	} else {
 		return element
 	}
 	return ref
 }
+
+// GetReferenceWithURI gets a reference of an identifiable, with its selfURI
+//
+// The selfURI is built like Decorate does, from the rootpath, the resource name, and the identifier of the element.
+//
+// If the element cannot be identified, GetReference is used.
+//
+// Example:
+//
+//	core.GetReferenceWithURI(user, "/api/v1") // {"id": "1234", "selfURI": "/api/v1/users/1234"}
+func GetReferenceWithURI(element any, rootpath string) any {
+	id, ok := getIdentifier(element)
+	if !ok {
+		return GetReference(element)
+	}
+	return reference(element, path.Join(rootpath, resourceNameOf(element), id))
+}
+
+// reference gets the reference of an identifiable with the given selfURI
+func reference(element any, selfURI string) any {
+	id, _ := getIdentifier(element)
+	return struct {
+		ID      string `json:"id"`
+		SelfURI string `json:"selfURI"`
+	}{ID: id, SelfURI: selfURI}
+}
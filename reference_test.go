@@ -53,3 +53,14 @@ func TestUnknownReference(t *testing.T) {
 	assert.NoError(t, err)
 	assert.JSONEq(t, expected, string(payload))
 }
+
+func TestReferenceWithURI(t *testing.T) {
+	stuff := Something4{ID: "string-id-123"}
+	payload, err := json.Marshal(core.GetReferenceWithURI(stuff, "/api/v1"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"string-id-123","selfURI":"/api/v1/something4s/string-id-123"}`, string(payload))
+
+	payload, err = json.Marshal(core.GetReferenceWithURI(Something2{Data: "stringer-id-456"}, "/api/v1"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"stringer-id-456"}`, string(payload))
+}
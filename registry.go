@@ -31,6 +31,13 @@ func (registry TypeRegistry) SupportedTypes() []string {
 	return supportedTypes
 }
 
+// UnmarshalTyped unmarshal a payload into a Type Carrier, like UnmarshalJSON
+//
+// implements TypeUnmarshaler
+func (registry TypeRegistry) UnmarshalTyped(payload []byte, typetag ...string) (any, error) {
+	return registry.UnmarshalJSON(payload, typetag...)
+}
+
 // UnmarshalJSON unmarshal a payload into a Type Carrier
 //
 // The interface that is returned contains a pointer to the TypeCarrier structure.
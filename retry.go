@@ -0,0 +1,256 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JitterStrategy tells how Retry randomizes the delays between attempts
+//
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type JitterStrategy int
+
+const (
+	// JitterStrategyFull waits a random delay between 0 and the exponential delay
+	JitterStrategyFull JitterStrategy = iota
+	// JitterStrategyEqual waits half the exponential delay plus a random delay up to the other half
+	JitterStrategyEqual
+	// JitterStrategyDecorrelated waits a random delay between the initial delay and 3 times the previous delay
+	JitterStrategyDecorrelated
+	// JitterStrategyNone waits the exponential delay
+	JitterStrategyNone
+)
+
+// RetryPolicy tells how Retry retries an operation
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, DefaultRetryAttempts is used if zero, there is no maximum if negative
+	MaxAttempts int
+
+	// MaxElapsedTime is the time after which Retry gives up, there is no limit if zero
+	MaxElapsedTime time.Duration
+
+	// InitialDelay is the delay after the first attempt, DefaultRetryInitialDelay is used if zero
+	InitialDelay time.Duration
+
+	// MaxDelay caps the delays between attempts, DefaultRetryMaxDelay is used if zero
+	MaxDelay time.Duration
+
+	// Jitter tells how the delays are randomized
+	Jitter JitterStrategy
+
+	// Retryable tells if an error is worth another attempt
+	//
+	// If nil, errors that implement Retryable() bool or Temporary() bool are retried when they return true,
+	// and the other errors are always retried.
+	Retryable func(err error) bool
+
+	// OnRetry is called after a failed attempt, before waiting for the given delay
+	OnRetry func(attempt int, err error, delay time.Duration)
+
+	// Clock is used to wait between attempts, the RealClock is used if nil
+	Clock Clock
+
+	// Rand is the random source of the jitter, the global source is used if nil
+	Rand *rand.Rand
+}
+
+// RetryError is returned by Retry when it gives up, it wraps the error of the last attempt
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+// RetryAfterError is an error that tells when the operation can be retried, like a Retry-After HTTP header
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+var (
+	// DefaultRetryAttempts is the maximum number of attempts of a RetryPolicy without MaxAttempts
+	DefaultRetryAttempts = 3
+	// DefaultRetryInitialDelay is the initial delay of a RetryPolicy without InitialDelay
+	DefaultRetryInitialDelay = 100 * time.Millisecond
+	// DefaultRetryMaxDelay is the maximum delay of a RetryPolicy without MaxDelay
+	DefaultRetryMaxDelay = 30 * time.Second
+)
+
+// Retry runs an operation until it succeeds or the policy gives up
+//
+// The delays between attempts grow exponentially (see ExponentialBackoff) and are randomized by the Jitter strategy.
+// If an error carries a RetryAfter() time.Duration method (like RetryAfterError), Retry waits at least that long.
+//
+// When Retry gives up, it returns a RetryError with the error of the last attempt.
+// If the context is cancelled while waiting, the RetryError also wraps the context error.
+//
+// Example:
+//
+//	user, err := core.Retry(ctx, func(ctx context.Context) (*User, error) {
+//		return client.GetUser(ctx, id)
+//	}, core.RetryPolicy{MaxAttempts: 5, MaxElapsedTime: time.Minute})
+func Retry[T any](ctx context.Context, operation func(ctx context.Context) (T, error), policy RetryPolicy) (T, error) {
+	clock := policy.Clock
+	if clock == nil {
+		clock = RealClock{}
+	}
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultRetryAttempts
+	}
+	start := clock.Now()
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		result, err := operation(ctx)
+		if err == nil {
+			return result, nil
+		}
+		var zero T
+		if ctx.Err() != nil || !policy.retryable(err) || (maxAttempts > 0 && attempt >= maxAttempts) {
+			return zero, RetryError{Attempts: attempt, Err: err}
+		}
+		delay = policy.delay(attempt, delay)
+		var retryAfter interface{ RetryAfter() time.Duration }
+		if errors.As(err, &retryAfter) {
+			delay = max(delay, retryAfter.RetryAfter())
+		}
+		if policy.MaxElapsedTime > 0 && clock.Now().Add(delay).Sub(start) > policy.MaxElapsedTime {
+			return zero, RetryError{Attempts: attempt, Err: err}
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, err, delay)
+		}
+
+		timer := clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, RetryError{Attempts: attempt, Err: fmt.Errorf("%w: %w", ctx.Err(), err)}
+		case <-timer.C():
+		}
+	}
+}
+
+// ParseRetryAfter parses the value of a Retry-After HTTP header, in seconds or as an HTTP date
+//
+// An HTTP date is relative to the given time, a date in the past gives 0.
+//
+// Example:
+//
+//	if response.StatusCode == http.StatusTooManyRequests {
+//		after, _ := core.ParseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+//		return core.RetryAfterError{Err: errors.New("too many requests"), After: after}
+//	}
+func ParseRetryAfter(value string, now time.Time) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf(`invalid Retry-After "%s": negative delay`, value)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, fmt.Errorf(`invalid Retry-After "%s": %w`, value, err)
+	}
+	return max(date.Sub(now), 0), nil
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err RetryError) Error() string {
+	if err.Attempts == 1 {
+		return fmt.Sprintf("failed after 1 attempt: %s", err.Err)
+	}
+	return fmt.Sprintf("failed after %d attempts: %s", err.Attempts, err.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (err RetryError) Unwrap() error {
+	return err.Err
+}
+
+// Error returns the string version of this error
+//
+// implements error interface
+func (err RetryAfterError) Error() string {
+	if err.Err == nil {
+		return fmt.Sprintf("retry after %s", err.After)
+	}
+	return err.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (err RetryAfterError) Unwrap() error {
+	return err.Err
+}
+
+// RetryAfter returns the delay to wait before retrying
+func (err RetryAfterError) RetryAfter() time.Duration {
+	return err.After
+}
+
+// Retryable tells that the operation can be retried
+func (err RetryAfterError) Retryable() bool {
+	return true
+}
+
+// retryable tells if the policy retries the given error
+func (policy RetryPolicy) retryable(err error) bool {
+	if policy.Retryable != nil {
+		return policy.Retryable(err)
+	}
+	var retryable interface{ Retryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+	return true
+}
+
+// delay returns the delay to wait after the given attempt, previous is the delay after the previous attempt
+func (policy RetryPolicy) delay(attempt int, previous time.Duration) time.Duration {
+	initialDelay := policy.InitialDelay
+	if initialDelay <= 0 {
+		initialDelay = DefaultRetryInitialDelay
+	}
+	maxDelay := policy.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+	random := rand.Int64N
+	if policy.Rand != nil {
+		random = policy.Rand.Int64N
+	}
+	// Beyond this attempt the exponential delay is capped anyway, stopping there avoids overflows
+	attempt = min(attempt, bits.Len64(uint64(maxDelay/initialDelay))+1)
+	between := func(low, high time.Duration) time.Duration {
+		if high <= low {
+			return low
+		}
+		return low + time.Duration(random(int64(high-low)+1))
+	}
+
+	switch policy.Jitter {
+	case JitterStrategyEqual:
+		delay := ExponentialBackoffWithRand(policy.Rand, attempt, initialDelay, maxDelay, 0)
+		return between(delay/2, delay)
+	case JitterStrategyDecorrelated:
+		return min(between(initialDelay, max(previous, initialDelay)*3), maxDelay)
+	case JitterStrategyNone:
+		return ExponentialBackoffWithRand(policy.Rand, attempt, initialDelay, maxDelay, 0)
+	default:
+		return between(0, ExponentialBackoffWithRand(policy.Rand, attempt, initialDelay, maxDelay, 0))
+	}
+}
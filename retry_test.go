@@ -0,0 +1,211 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TemporaryError struct {
+	temporary bool
+}
+
+func (err TemporaryError) Error() string {
+	return "temporary error"
+}
+
+func (err TemporaryError) Temporary() bool {
+	return err.temporary
+}
+
+// advanceWhileWaiting advances the clock by small steps while Retry waits, until stopped
+func advanceWhileWaiting(clock *core.FakeClock, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+			if clock.PendingTimers() > 0 {
+				clock.Advance(100 * time.Millisecond)
+			} else {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+}
+
+func newRetryPolicy(t *testing.T) (core.RetryPolicy, *core.FakeClock) {
+	clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	go advanceWhileWaiting(clock, stop)
+	return core.RetryPolicy{
+		Clock:        clock,
+		Rand:         rand.New(rand.NewPCG(1, 2)),
+		InitialDelay: time.Second,
+		MaxDelay:     10 * time.Second,
+		Jitter:       core.JitterStrategyNone,
+	}, clock
+}
+
+func TestCanRetry(t *testing.T) {
+	policy, _ := newRetryPolicy(t)
+	policy.MaxAttempts = 5
+	var delays []time.Duration
+	policy.OnRetry = func(attempt int, err error, delay time.Duration) {
+		assert.Equal(t, len(delays)+1, attempt)
+		assert.EqualError(t, err, "temporary error")
+		delays = append(delays, delay)
+	}
+
+	attempts := 0
+	value, err := core.Retry(context.Background(), func(ctx context.Context) (string, error) {
+		if attempts++; attempts < 5 {
+			return "", TemporaryError{temporary: true}
+		}
+		return "hello", nil
+	}, policy)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", value)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}, delays)
+}
+
+func TestShouldGiveUpRetryingAfterMaxAttempts(t *testing.T) {
+	policy, _ := newRetryPolicy(t)
+	attempts := 0
+	_, err := core.Retry(context.Background(), func(ctx context.Context) (int, error) {
+		attempts++
+		return 0, errors.New("boom")
+	}, policy)
+	require.Error(t, err)
+	assert.Equal(t, core.DefaultRetryAttempts, attempts)
+	assert.EqualError(t, err, "failed after 3 attempts: boom")
+
+	var retryError core.RetryError
+	require.ErrorAs(t, err, &retryError)
+	assert.Equal(t, 3, retryError.Attempts)
+}
+
+func TestShouldGiveUpRetryingAfterMaxElapsedTime(t *testing.T) {
+	policy, clock := newRetryPolicy(t)
+	policy.MaxAttempts = -1
+	policy.MaxElapsedTime = 10 * time.Second
+	start := clock.Now()
+	var waited time.Duration
+	policy.OnRetry = func(attempt int, err error, delay time.Duration) { waited += delay }
+
+	_, err := core.Retry(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errors.New("boom")
+	}, policy)
+	assert.EqualError(t, err, "failed after 4 attempts: boom") // 1s + 2s + 4s, the next 8s would be too late
+	assert.Equal(t, 7*time.Second, waited)
+	assert.True(t, clock.Now().After(start))
+}
+
+func TestShouldNotRetryPermanentErrors(t *testing.T) {
+	policy, _ := newRetryPolicy(t)
+	attempts := 0
+	_, err := core.Retry(context.Background(), func(ctx context.Context) (int, error) {
+		attempts++
+		return 0, TemporaryError{temporary: false}
+	}, policy)
+	assert.Equal(t, 1, attempts)
+	assert.EqualError(t, err, "failed after 1 attempt: temporary error")
+
+	attempts = 0
+	notFound := errors.New("not found")
+	policy.Retryable = func(err error) bool { return !errors.Is(err, notFound) }
+	_, err = core.Retry(context.Background(), func(ctx context.Context) (int, error) {
+		attempts++
+		return 0, notFound
+	}, policy)
+	assert.Equal(t, 1, attempts)
+	assert.ErrorIs(t, err, notFound)
+}
+
+func TestRetryShouldHonorRetryAfter(t *testing.T) {
+	policy, _ := newRetryPolicy(t)
+	var delays []time.Duration
+	policy.OnRetry = func(attempt int, err error, delay time.Duration) { delays = append(delays, delay) }
+	attempts := 0
+	_, err := core.Retry(context.Background(), func(ctx context.Context) (int, error) {
+		if attempts++; attempts == 1 {
+			return 0, core.RetryAfterError{Err: errors.New("too many requests"), After: 2 * time.Minute}
+		}
+		return 0, core.RetryAfterError{After: 100 * time.Millisecond}
+	}, policy)
+	assert.EqualError(t, err, "failed after 3 attempts: retry after 100ms")
+	assert.Equal(t, []time.Duration{2 * time.Minute, 2 * time.Second}, delays)
+}
+
+func TestCanCancelRetry(t *testing.T) {
+	clock := core.NewFakeClock(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := core.RetryPolicy{Clock: clock, MaxAttempts: -1}
+	policy.OnRetry = func(attempt int, err error, delay time.Duration) { cancel() }
+
+	_, err := core.Retry(ctx, func(ctx context.Context) (int, error) {
+		return 0, errors.New("boom")
+	}, policy)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, "failed after 1 attempt: context canceled: boom")
+	assert.Equal(t, 0, clock.PendingTimers(), "The timer should have been stopped")
+}
+
+func TestCanRetryWithJitterStrategies(t *testing.T) {
+	strategies := []struct {
+		jitter core.JitterStrategy
+		low    func(attempt int, exponential time.Duration) time.Duration
+		high   func(attempt int, exponential time.Duration) time.Duration
+	}{
+		{core.JitterStrategyFull, func(int, time.Duration) time.Duration { return 0 }, func(_ int, d time.Duration) time.Duration { return d }},
+		{core.JitterStrategyEqual, func(_ int, d time.Duration) time.Duration { return d / 2 }, func(_ int, d time.Duration) time.Duration { return d }},
+		{core.JitterStrategyDecorrelated, func(int, time.Duration) time.Duration { return time.Second }, func(int, time.Duration) time.Duration { return 10 * time.Second }},
+	}
+	for _, strategy := range strategies {
+		run := func() []time.Duration {
+			policy, _ := newRetryPolicy(t)
+			policy.MaxAttempts = 8
+			policy.Jitter = strategy.jitter
+			var delays []time.Duration
+			policy.OnRetry = func(attempt int, err error, delay time.Duration) {
+				exponential := min(time.Second<<(attempt-1), 10*time.Second)
+				assert.GreaterOrEqual(t, delay, strategy.low(attempt, exponential), "Jitter %d, attempt %d", strategy.jitter, attempt)
+				assert.LessOrEqual(t, delay, strategy.high(attempt, exponential), "Jitter %d, attempt %d", strategy.jitter, attempt)
+				delays = append(delays, delay)
+			}
+			_, _ = core.Retry(context.Background(), func(ctx context.Context) (int, error) { return 0, errors.New("boom") }, policy)
+			return delays
+		}
+		first := run()
+		assert.Len(t, first, 7)
+		assert.Equal(t, first, run(), "The same seed should give the same delays with jitter %d", strategy.jitter)
+	}
+}
+
+func TestCanParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	after, err := core.ParseRetryAfter("120", now)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, after)
+
+	after, err = core.ParseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, after)
+
+	after, err = core.ParseRetryAfter(now.Add(-time.Hour).Format(http.TimeFormat), now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), after)
+
+	for _, value := range []string{"", "-5", "soon"} {
+		_, err = core.ParseRetryAfter(value, now)
+		assert.Error(t, err, "Should have failed to parse %s", value)
+	}
+}
@@ -0,0 +1,314 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule tells when a scheduled job runs
+type Schedule interface {
+	// Next returns the first run time after the given time, or the zero time if there is none
+	Next(after time.Time) time.Time
+}
+
+// CronSchedule is a Schedule described by a cron expression
+//
+// See ParseCron for the supported expressions.
+type CronSchedule struct {
+	Spec     string
+	Location *time.Location
+
+	seconds    uint64
+	minutes    uint64
+	hours      uint64
+	days       uint64
+	months     uint64
+	weekdays   uint64
+	anyDay     bool
+	anyWeekday bool
+}
+
+// IntervalSchedule is a Schedule described by an ISO 8601 repeating interval
+//
+// The runs happen at Start, then every Period, Repetitions times (or forever if Repetitions is negative).
+// If Start is zero, the runs happen every Period after the given time.
+type IntervalSchedule struct {
+	Spec        string
+	Start       time.Time
+	Period      time.Duration
+	Repetitions int
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// ParseSchedule parses a cron expression (see ParseCron), an ISO 8601 repeating interval (see ParseInterval),
+// or "@every" followed by a duration (e.g.: "@every 5m", "@every PT1H")
+//
+// The location is used by cron expressions, time.Local is used if nil.
+func ParseSchedule(spec string, location *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if every, found := strings.CutPrefix(spec, "@every "); found {
+		period, err := ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, fmt.Errorf(`invalid schedule "%s": %w`, spec, err)
+		}
+		if period <= 0 {
+			return nil, fmt.Errorf(`invalid schedule "%s": the duration must be positive`, spec)
+		}
+		return &IntervalSchedule{Spec: spec, Period: period, Repetitions: -1}, nil
+	}
+	if strings.HasPrefix(spec, "R") && strings.Contains(spec, "/") {
+		return ParseInterval(spec)
+	}
+	return ParseCron(spec, location)
+}
+
+// ParseCron parses a cron expression
+//
+// The expression has 5 fields (minute, hour, day of month, month, day of week) or 6 fields (with the second first).
+// Fields accept "*", "?", values, ranges ("1-5"), steps ("*/15", "0-30/10"), and lists ("1,15").
+// Months and days of week accept their English abbreviations ("JAN", "MON-FRI"), Sunday is 0 or 7.
+// Like cron, when both the day of month and the day of week are restricted, a day matches either of them.
+//
+// The macros @yearly, @annually, @monthly, @weekly, @daily, @midnight, and @hourly are supported.
+//
+// The location is the time zone of the expression, time.Local is used if nil.
+//
+// Example:
+//
+//	schedule, err := core.ParseCron("0 3 * * MON-FRI", paris) // at 03:00 in Paris, on weekdays
+func ParseCron(spec string, location *time.Location) (*CronSchedule, error) {
+	if location == nil {
+		location = time.Local
+	}
+	expression := strings.TrimSpace(spec)
+	if macro, found := cronMacros[strings.ToLower(expression)]; found {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf(`invalid cron spec "%s": expected 5 or 6 fields, got %d`, spec, len(fields))
+	}
+
+	schedule := &CronSchedule{Spec: spec, Location: location}
+	var err error
+	if schedule.seconds, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf(`invalid cron spec "%s": second: %w`, spec, err)
+	}
+	if schedule.minutes, err = parseCronField(fields[1], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf(`invalid cron spec "%s": minute: %w`, spec, err)
+	}
+	if schedule.hours, err = parseCronField(fields[2], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf(`invalid cron spec "%s": hour: %w`, spec, err)
+	}
+	if schedule.days, err = parseCronField(fields[3], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf(`invalid cron spec "%s": day of month: %w`, spec, err)
+	}
+	if schedule.months, err = parseCronField(fields[4], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf(`invalid cron spec "%s": month: %w`, spec, err)
+	}
+	if schedule.weekdays, err = parseCronField(fields[5], 0, 7, cronWeekdayNames); err != nil {
+		return nil, fmt.Errorf(`invalid cron spec "%s": day of week: %w`, spec, err)
+	}
+	if schedule.weekdays&(1<<7) != 0 { // 7 is also Sunday
+		schedule.weekdays |= 1
+	}
+	schedule.anyDay = fields[3] == "*" || fields[3] == "?"
+	schedule.anyWeekday = fields[5] == "*" || fields[5] == "?"
+	return schedule, nil
+}
+
+// ParseInterval parses an ISO 8601 repeating interval
+//
+// The supported forms are "R<n>/<start>/<duration>" and "R<n>/<duration>", n is optional for an endless repetition.
+// The start is parsed with ParseTime and the duration with ParseDuration.
+//
+// Example:
+//
+//	schedule, err := core.ParseInterval("R5/2026-01-01T00:00:00Z/PT1H") // 5 runs, every hour
+func ParseInterval(spec string) (*IntervalSchedule, error) {
+	parts := strings.Split(strings.TrimSpace(spec), "/")
+	if len(parts) < 2 || len(parts) > 3 || !strings.HasPrefix(parts[0], "R") {
+		return nil, fmt.Errorf(`invalid interval "%s": expected R<n>/<start>/<duration> or R<n>/<duration>`, spec)
+	}
+	schedule := &IntervalSchedule{Spec: spec, Repetitions: -1}
+	if count := parts[0][1:]; len(count) > 0 {
+		repetitions, err := strconv.Atoi(count)
+		if err != nil || repetitions < -1 {
+			return nil, fmt.Errorf(`invalid interval "%s": invalid repetitions "%s"`, spec, count)
+		}
+		schedule.Repetitions = repetitions
+	}
+	if len(parts) == 3 {
+		start, err := ParseTime(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf(`invalid interval "%s": %w`, spec, err)
+		}
+		schedule.Start = start.AsTime()
+	}
+	period, err := ParseDuration(parts[len(parts)-1])
+	if err != nil {
+		return nil, fmt.Errorf(`invalid interval "%s": %w`, spec, err)
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf(`invalid interval "%s": the duration must be positive`, spec)
+	}
+	schedule.Period = period
+	return schedule, nil
+}
+
+// Next returns the first run time after the given time, or the zero time if there is none in the next 5 years
+//
+// implements Schedule
+func (schedule CronSchedule) Next(after time.Time) time.Time {
+	location := schedule.Location
+	if location == nil {
+		location = time.Local
+	}
+	next := after.In(location).Truncate(time.Second).Add(time.Second)
+	limit := next.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		year, month, day := next.Date()
+		hour, minute, _ := next.Clock()
+		switch {
+		case schedule.months&(1<<uint(month)) == 0:
+			next = time.Date(year, month+1, 1, 0, 0, 0, 0, location)
+		case !schedule.matchDay(next):
+			next = time.Date(year, month, day+1, 0, 0, 0, 0, location)
+		case schedule.hours&(1<<uint(hour)) == 0:
+			next = time.Date(year, month, day, hour+1, 0, 0, 0, location)
+		case schedule.minutes&(1<<uint(minute)) == 0:
+			next = next.Truncate(time.Minute).Add(time.Minute)
+		case schedule.seconds&(1<<uint(next.Second())) == 0:
+			next = next.Add(time.Second)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// String returns the cron expression of the CronSchedule
+//
+// implements fmt.Stringer
+func (schedule CronSchedule) String() string {
+	return schedule.Spec
+}
+
+// Next returns the first run time after the given time, or the zero time if the repetitions are over
+//
+// implements Schedule
+func (schedule IntervalSchedule) Next(after time.Time) time.Time {
+	if schedule.Period <= 0 {
+		return time.Time{}
+	}
+	if schedule.Start.IsZero() {
+		return after.Add(schedule.Period)
+	}
+	if after.Before(schedule.Start) {
+		if schedule.Repetitions == 0 {
+			return time.Time{}
+		}
+		return schedule.Start
+	}
+	count := int64(after.Sub(schedule.Start)/schedule.Period) + 1
+	if schedule.Repetitions >= 0 && count >= int64(schedule.Repetitions) {
+		return time.Time{}
+	}
+	return schedule.Start.Add(time.Duration(count) * schedule.Period)
+}
+
+// String returns the ISO 8601 repeating interval of the IntervalSchedule
+//
+// implements fmt.Stringer
+func (schedule IntervalSchedule) String() string {
+	return schedule.Spec
+}
+
+// matchDay tells if the day of the given time matches the day of month and day of week fields
+func (schedule CronSchedule) matchDay(t time.Time) bool {
+	dayMatches := schedule.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := schedule.weekdays&(1<<uint(t.Weekday())) != 0
+	if schedule.anyDay || schedule.anyWeekday {
+		return dayMatches && weekdayMatches
+	}
+	return dayMatches || weekdayMatches
+}
+
+// parseCronField parses a field of a cron expression into a bitset of its values
+func parseCronField(field string, low, high int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf(`invalid step "%s"`, stepPart)
+			}
+		}
+		start, end := low, high
+		if rangePart != "*" && rangePart != "?" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(first, low, high, names); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseCronValue(last, low, high, names); err != nil {
+					return 0, err
+				}
+				if end == 0 && start > 0 && high == 7 { // ranges can end on Sunday: FRI-SUN
+					end = 7
+				}
+			} else if hasStep {
+				end = high
+			}
+			if end < start {
+				return 0, fmt.Errorf(`invalid range "%s"`, rangePart)
+			}
+		}
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, low, high int, names map[string]int) (int, error) {
+	if named, found := names[strings.ToUpper(value)]; found {
+		return named, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf(`invalid value "%s"`, value)
+	}
+	if number < low || number > high {
+		return 0, fmt.Errorf(`value %d out of range [%d-%d]`, number, low, high)
+	}
+	return number, nil
+}
@@ -0,0 +1,108 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanParseCron(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	monday := time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		spec     string
+		location *time.Location
+		after    time.Time
+		expected time.Time
+	}{
+		{"0 3 * * MON-FRI", time.UTC, monday, time.Date(2026, time.March, 3, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * MON-FRI", time.UTC, time.Date(2026, time.March, 6, 10, 0, 0, 0, time.UTC), time.Date(2026, time.March, 9, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * FRI-SUN", time.UTC, monday, time.Date(2026, time.March, 6, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * 7", time.UTC, monday, time.Date(2026, time.March, 8, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * 0-0", time.UTC, monday, time.Date(2026, time.March, 8, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * SUN-SUN", time.UTC, monday, time.Date(2026, time.March, 8, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * SUN-MON", time.UTC, time.Date(2026, time.March, 3, 10, 0, 0, 0, time.UTC), time.Date(2026, time.March, 8, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.UTC, monday.Add(time.Minute), time.Date(2026, time.March, 2, 10, 15, 0, 0, time.UTC)},
+		{"30 */10 * * * *", time.UTC, monday, time.Date(2026, time.March, 2, 10, 0, 30, 0, time.UTC)},
+		{"0 0 12 1,15 * *", time.UTC, monday, time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN ?", time.UTC, monday, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 1", time.UTC, monday, time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)},                                          // Monday or the 13th
+		{"0 0 13 * 5", time.UTC, monday, time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC)},                                          // Friday or the 13th
+		{"0 0 31 2 *", time.UTC, monday, time.Time{}},                                                                                   // never
+		{"0 3 * * *", paris, monday, time.Date(2026, time.March, 3, 3, 0, 0, 0, paris)},                                                 // 02:00 UTC
+		{"30 2 * * *", paris, time.Date(2026, time.March, 28, 12, 0, 0, 0, paris), time.Date(2026, time.March, 30, 2, 30, 0, 0, paris)}, // 02:30 does not exist on March 29
+		{"@hourly", time.UTC, monday, time.Date(2026, time.March, 2, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.UTC, monday, time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.UTC, monday, time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.UTC, monday, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.UTC, monday, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := core.ParseCron(test.spec, test.location)
+		require.NoError(t, err, "Failed to parse %s", test.spec)
+		next := schedule.Next(test.after)
+		assert.True(t, test.expected.Equal(next), "%s after %s: expected %s, got %s", test.spec, test.after, test.expected, next)
+		assert.Equal(t, test.spec, schedule.String())
+	}
+}
+
+func TestShouldFailParsingInvalidCron(t *testing.T) {
+	specs := []string{"", "* * * *", "* * * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * FOO *", "a * * * *"}
+	for _, spec := range specs {
+		_, err := core.ParseCron(spec, nil)
+		assert.Error(t, err, "Should have failed to parse %s", spec)
+	}
+}
+
+func TestCanParseInterval(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	schedule, err := core.ParseInterval("R3/2026-01-01T00:00:00Z/PT1H")
+	require.NoError(t, err)
+	assert.Equal(t, 3, schedule.Repetitions)
+	assert.Equal(t, time.Hour, schedule.Period)
+	assert.True(t, start.Equal(schedule.Start))
+	assert.True(t, start.Equal(schedule.Next(start.Add(-time.Minute))))
+	assert.True(t, start.Add(time.Hour).Equal(schedule.Next(start)))
+	assert.True(t, start.Add(2*time.Hour).Equal(schedule.Next(start.Add(90*time.Minute))))
+	assert.True(t, schedule.Next(start.Add(2*time.Hour)).IsZero(), "There should be no more runs after 3 repetitions")
+
+	schedule, err = core.ParseInterval("R/P1D")
+	require.NoError(t, err)
+	assert.Equal(t, -1, schedule.Repetitions)
+	assert.True(t, start.Add(24*time.Hour).Equal(schedule.Next(start)))
+
+	for _, spec := range []string{"R", "P1D", "R/P1D/P1D/P1D", "Rx/P1D", "R/not-a-date/P1D", "R/PT0S", "R/hello"} {
+		_, err := core.ParseInterval(spec)
+		assert.Error(t, err, "Should have failed to parse %s", spec)
+	}
+}
+
+func TestCanParseSchedule(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := core.ParseSchedule("@every 5m", nil)
+	require.NoError(t, err)
+	assert.True(t, start.Add(5*time.Minute).Equal(schedule.Next(start)))
+
+	schedule, err = core.ParseSchedule("@every PT1H", nil)
+	require.NoError(t, err)
+	assert.True(t, start.Add(time.Hour).Equal(schedule.Next(start)))
+
+	schedule, err = core.ParseSchedule("R2/2026-01-01T00:00:00Z/PT1H", nil)
+	require.NoError(t, err)
+	assert.IsType(t, &core.IntervalSchedule{}, schedule)
+
+	schedule, err = core.ParseSchedule("0 3 * * *", time.UTC)
+	require.NoError(t, err)
+	assert.IsType(t, &core.CronSchedule{}, schedule)
+
+	_, err = core.ParseSchedule("@every never", nil)
+	assert.Error(t, err)
+	_, err = core.ParseSchedule("@every -5m", nil)
+	assert.Error(t, err)
+}
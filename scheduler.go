@@ -0,0 +1,308 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MissedRunPolicy tells what a Scheduler does with the runs it missed, like after the computer was suspended
+type MissedRunPolicy int
+
+const (
+	// MissedRunPolicySkip skips the missed runs, the jobs run again at their next run time
+	MissedRunPolicySkip MissedRunPolicy = iota
+	// MissedRunPolicyCatchUp runs the missed runs as soon as possible, in order
+	MissedRunPolicyCatchUp
+)
+
+// Scheduler runs named jobs on Schedules
+//
+// The runs of a job never overlap, the runs that are due while a run is in flight are queued.
+// A run is missed when the Scheduler sees it later than the Tolerance, the MissedRuns policy tells what to do with it.
+//
+// Errors returned by the jobs, and their panics as JobPanicError, are given to OnError.
+// If OnError is nil, they are logged in the default slog.Logger.
+//
+// The Scheduler is an http.Handler that sends the ScheduledJobStatus of its jobs.
+//
+// Example:
+//
+//	scheduler := core.NewScheduler()
+//	scheduler.Location = paris
+//	err := scheduler.Add("purge", "0 3 * * MON-FRI", purge)
+//	err = scheduler.Add("report", "R/2026-01-01T08:00:00Z/P1D", report)
+//	err = scheduler.Start(ctx)
+//	defer scheduler.Stop()
+//	mux.Handle("GET /admin/jobs", scheduler)
+type Scheduler struct {
+	// Clock is used to tell the time and tick, the RealClock is used if nil
+	Clock Clock
+
+	// Location is the time zone of the cron expressions, time.Local is used if nil
+	Location *time.Location
+
+	// MissedRuns tells what to do with the missed runs
+	MissedRuns MissedRunPolicy
+
+	// Tolerance is how late a run can be before it is missed, one minute is used if zero
+	Tolerance time.Duration
+
+	// OnError is called with the errors of the runs
+	OnError func(name string, err error)
+
+	jobs    map[string]*scheduledJob
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	workers sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+// ScheduledJobStatus describes a job of a Scheduler
+type ScheduledJobStatus struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	NextRun   time.Time `json:"nextRun,omitzero"`
+	LastRun   time.Time `json:"lastRun,omitzero"`
+	LastError string    `json:"lastError,omitempty"`
+	Runs      int64     `json:"runs"`
+	Missed    int64     `json:"missed"`
+	Running   bool      `json:"running"`
+}
+
+type scheduledJob struct {
+	status   ScheduledJobStatus
+	schedule Schedule
+	run      JobFunc
+	queue    chan time.Time
+}
+
+// maxQueuedRuns is the maximum number of runs a job can have in its queue, the runs beyond are missed
+const maxQueuedRuns = 100
+
+// NewScheduler creates a new Scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{jobs: map[string]*scheduledJob{}}
+}
+
+// Add adds a job to the Scheduler with a schedule spec (see ParseSchedule)
+//
+// Jobs can be added before or after the Scheduler is started. A job with the same name is replaced.
+func (scheduler *Scheduler) Add(name, spec string, run JobFunc) error {
+	schedule, err := ParseSchedule(spec, scheduler.Location)
+	if err != nil {
+		return err
+	}
+	return scheduler.AddSchedule(name, schedule, run)
+}
+
+// AddSchedule adds a job to the Scheduler with a Schedule
+//
+// An IntervalSchedule without Start starts when the job is added, its first run is one Period later.
+func (scheduler *Scheduler) AddSchedule(name string, schedule Schedule, run JobFunc) error {
+	if len(name) == 0 {
+		return fmt.Errorf("the name of a scheduled job cannot be empty")
+	}
+	if schedule == nil || run == nil {
+		return fmt.Errorf(`the scheduled job "%s" needs a schedule and a func`, name)
+	}
+	now := scheduler.clock().Now()
+	if interval, ok := schedule.(*IntervalSchedule); ok && interval.Start.IsZero() {
+		anchored := *interval
+		anchored.Start = now.Add(interval.Period)
+		schedule = &anchored
+	}
+	job := &scheduledJob{
+		status:   ScheduledJobStatus{Name: name, Schedule: fmt.Sprint(schedule), NextRun: schedule.Next(now)},
+		schedule: schedule,
+		run:      run,
+		queue:    make(chan time.Time, maxQueuedRuns),
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if scheduler.jobs == nil {
+		scheduler.jobs = map[string]*scheduledJob{}
+	}
+	if previous, found := scheduler.jobs[name]; found {
+		close(previous.queue)
+	}
+	scheduler.jobs[name] = job
+	if scheduler.ctx != nil {
+		scheduler.startWorker(job)
+	}
+	return nil
+}
+
+// Remove removes a job from the Scheduler, its run in flight is not interrupted
+func (scheduler *Scheduler) Remove(name string) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if job, found := scheduler.jobs[name]; found {
+		close(job.queue)
+		delete(scheduler.jobs, name)
+	}
+}
+
+// Start starts the Scheduler
+//
+// The next run times of the jobs are computed from now, the runs before are not missed.
+// The Scheduler runs until the context is cancelled or Stop is called. A Scheduler can be started only once, ErrJobStarted is returned otherwise.
+func (scheduler *Scheduler) Start(ctx context.Context) error {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if scheduler.ctx != nil {
+		return ErrJobStarted
+	}
+	scheduler.ctx, scheduler.cancel = context.WithCancel(ctx)
+	scheduler.done = make(chan struct{})
+	now := scheduler.clock().Now()
+	for _, job := range scheduler.jobs {
+		job.status.NextRun = job.schedule.Next(now)
+		scheduler.startWorker(job)
+	}
+	go scheduler.loop(scheduler.ctx, scheduler.clock().NewTicker(time.Second))
+	return nil
+}
+
+// Stop stops the Scheduler and waits for the runs in flight to finish
+func (scheduler *Scheduler) Stop() {
+	scheduler.mutex.Lock()
+	cancel, done := scheduler.cancel, scheduler.done
+	scheduler.mutex.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	scheduler.workers.Wait()
+}
+
+// NextRun returns the next run time of a job
+func (scheduler *Scheduler) NextRun(name string) (time.Time, bool) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if job, found := scheduler.jobs[name]; found {
+		return job.status.NextRun, true
+	}
+	return time.Time{}, false
+}
+
+// Status returns the ScheduledJobStatus of the jobs, sorted by name
+func (scheduler *Scheduler) Status() []ScheduledJobStatus {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	statuses := make([]ScheduledJobStatus, 0, len(scheduler.jobs))
+	for _, job := range scheduler.jobs {
+		statuses = append(statuses, job.status)
+	}
+	slices.SortFunc(statuses, func(a, b ScheduledJobStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return statuses
+}
+
+// ServeHTTP sends the ScheduledJobStatus of the jobs as JSON
+//
+// implements http.Handler
+func (scheduler *Scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	RespondWithJSON(w, http.StatusOK, scheduler.Status())
+}
+
+func (scheduler *Scheduler) clock() Clock {
+	if scheduler.Clock == nil {
+		return RealClock{}
+	}
+	return scheduler.Clock
+}
+
+func (scheduler *Scheduler) loop(ctx context.Context, ticker Ticker) {
+	defer close(scheduler.done)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			scheduler.dispatch(scheduler.clock().Now())
+		}
+	}
+}
+
+// dispatch queues the runs that are due
+func (scheduler *Scheduler) dispatch(now time.Time) {
+	tolerance := scheduler.Tolerance
+	if tolerance <= 0 {
+		tolerance = time.Minute
+	}
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	for _, job := range scheduler.jobs {
+		for next := job.status.NextRun; !next.IsZero() && !next.After(now); next = job.status.NextRun {
+			job.status.NextRun = job.schedule.Next(next)
+			if now.Sub(next) > tolerance && scheduler.MissedRuns == MissedRunPolicySkip {
+				job.status.Missed++
+				continue
+			}
+			select {
+			case job.queue <- next:
+			default:
+				job.status.Missed++
+			}
+		}
+	}
+}
+
+// startWorker starts the go routine that runs the queued runs of a job, the scheduler must be locked
+func (scheduler *Scheduler) startWorker(job *scheduledJob) {
+	ctx := scheduler.ctx
+	scheduler.workers.Add(1)
+	go func() {
+		defer scheduler.workers.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case at, ok := <-job.queue:
+				if !ok {
+					return
+				}
+				scheduler.execute(ctx, job, at)
+			}
+		}
+	}()
+}
+
+func (scheduler *Scheduler) execute(ctx context.Context, job *scheduledJob, at time.Time) {
+	scheduler.mutex.Lock()
+	job.status.Running = true
+	tick := job.status.Runs
+	scheduler.mutex.Unlock()
+
+	err := runJobFunc(ctx, job.run, tick, at)
+
+	scheduler.mutex.Lock()
+	job.status.Running = false
+	job.status.Runs++
+	job.status.LastRun = at
+	job.status.LastError = ""
+	if err != nil {
+		job.status.LastError = err.Error()
+	}
+	name := job.status.Name
+	scheduler.mutex.Unlock()
+
+	if err != nil {
+		if scheduler.OnError != nil {
+			scheduler.OnError(name, err)
+			return
+		}
+		slog.ErrorContext(ctx, "Scheduled job failed", "job", name, "error", err)
+	}
+}
@@ -0,0 +1,166 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduler(t *testing.T) (*core.Scheduler, *core.FakeClock) {
+	clock := core.NewFakeClock(time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC))
+	scheduler := core.NewScheduler()
+	scheduler.Clock = clock
+	scheduler.Location = time.UTC
+	return scheduler, clock
+}
+
+func recordRuns(runs chan<- time.Time) core.JobFunc {
+	return func(ctx context.Context, tick int64, at time.Time) error {
+		runs <- at
+		return nil
+	}
+}
+
+func TestCanScheduleJobs(t *testing.T) {
+	scheduler, clock := newTestScheduler(t)
+	cronRuns := make(chan time.Time, 10)
+	everyRuns := make(chan time.Time, 10)
+	require.NoError(t, scheduler.Add("cron", "0 */5 * * * *", recordRuns(cronRuns)))
+	require.NoError(t, scheduler.Add("every", "@every 2m", recordRuns(everyRuns)))
+	require.NoError(t, scheduler.Start(context.Background()))
+	defer scheduler.Stop()
+
+	next, found := scheduler.NextRun("cron")
+	require.True(t, found)
+	assert.Equal(t, time.Date(2026, time.March, 2, 10, 5, 0, 0, time.UTC), next)
+	next, _ = scheduler.NextRun("every")
+	assert.Equal(t, time.Date(2026, time.March, 2, 10, 2, 0, 0, time.UTC), next)
+	_, found = scheduler.NextRun("unknown")
+	assert.False(t, found)
+
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, time.Date(2026, time.March, 2, 10, 2, 0, 0, time.UTC), waitForScheduledRun(t, everyRuns))
+	clock.Advance(3 * time.Minute)
+	assert.Equal(t, time.Date(2026, time.March, 2, 10, 5, 0, 0, time.UTC), waitForScheduledRun(t, cronRuns))
+	assert.Equal(t, time.Date(2026, time.March, 2, 10, 4, 0, 0, time.UTC), waitForScheduledRun(t, everyRuns))
+
+	assert.Eventually(t, func() bool {
+		statuses := scheduler.Status()
+		return len(statuses) == 2 && statuses[0].Runs == 1 && statuses[1].Runs == 2
+	}, time.Second, time.Millisecond)
+	statuses := scheduler.Status()
+	assert.Equal(t, "cron", statuses[0].Name)
+	assert.Equal(t, "0 */5 * * * *", statuses[0].Schedule)
+	assert.Equal(t, time.Date(2026, time.March, 2, 10, 5, 0, 0, time.UTC), statuses[0].LastRun)
+	assert.Equal(t, time.Date(2026, time.March, 2, 10, 10, 0, 0, time.UTC), statuses[0].NextRun)
+
+	scheduler.Remove("cron")
+	assert.Len(t, scheduler.Status(), 1)
+}
+
+func TestSchedulerShouldSkipMissedRuns(t *testing.T) {
+	scheduler, clock := newTestScheduler(t)
+	runs := make(chan time.Time, 10)
+	require.NoError(t, scheduler.Add("every", "@every 1h", recordRuns(runs)))
+	require.NoError(t, scheduler.Start(context.Background()))
+	defer scheduler.Stop()
+
+	clock.Advance(5*time.Hour + 30*time.Minute) // like a suspended computer
+	assert.Eventually(t, func() bool { return scheduler.Status()[0].Missed == 5 }, time.Second, time.Millisecond)
+	select {
+	case at := <-runs:
+		assert.Failf(t, "the missed runs should have been skipped", "ran at %s", at)
+	case <-time.After(50 * time.Millisecond):
+	}
+	next, _ := scheduler.NextRun("every")
+	assert.Equal(t, time.Date(2026, time.March, 2, 16, 0, 0, 0, time.UTC), next)
+}
+
+func TestSchedulerShouldCatchUpMissedRuns(t *testing.T) {
+	scheduler, clock := newTestScheduler(t)
+	scheduler.MissedRuns = core.MissedRunPolicyCatchUp
+	runs := make(chan time.Time, 10)
+	require.NoError(t, scheduler.Add("report", "R3/2026-03-02T11:00:00Z/PT1H", recordRuns(runs)))
+	require.NoError(t, scheduler.Start(context.Background()))
+	defer scheduler.Stop()
+
+	clock.Advance(5 * time.Hour)
+	for hour := 11; hour <= 13; hour++ {
+		assert.Equal(t, time.Date(2026, time.March, 2, hour, 0, 0, 0, time.UTC), waitForScheduledRun(t, runs))
+	}
+	assert.Eventually(t, func() bool { return scheduler.Status()[0].Runs == 3 }, time.Second, time.Millisecond)
+	next, _ := scheduler.NextRun("report")
+	assert.True(t, next.IsZero(), "the interval should be over")
+}
+
+func TestSchedulerShouldReportErrors(t *testing.T) {
+	scheduler, clock := newTestScheduler(t)
+	errs := make(chan error, 2)
+	scheduler.OnError = func(name string, err error) {
+		assert.Equal(t, "failing", name)
+		errs <- err
+	}
+	require.NoError(t, scheduler.Add("failing", "@every 1m", func(ctx context.Context, tick int64, at time.Time) error {
+		if tick == 0 {
+			return errors.New("failed")
+		}
+		panic("boom")
+	}))
+	require.NoError(t, scheduler.Start(context.Background()))
+	defer scheduler.Stop()
+
+	clock.Advance(time.Minute)
+	assert.EqualError(t, <-errs, "failed")
+	clock.Advance(time.Minute)
+	var panicError core.JobPanicError
+	assert.ErrorAs(t, <-errs, &panicError)
+	assert.Eventually(t, func() bool { return scheduler.Status()[0].LastError == "job panicked: boom" }, time.Second, time.Millisecond)
+
+	assert.ErrorIs(t, scheduler.Start(context.Background()), core.ErrJobStarted)
+}
+
+func TestShouldFailAddingInvalidScheduledJob(t *testing.T) {
+	scheduler := core.NewScheduler()
+	noop := func(ctx context.Context, tick int64, at time.Time) error { return nil }
+	assert.Error(t, scheduler.Add("bogus", "* * *", noop))
+	assert.Error(t, scheduler.Add("", "@hourly", noop))
+	assert.Error(t, scheduler.Add("nil", "@hourly", nil))
+	scheduler.Stop() // stopping a scheduler that was not started is fine
+}
+
+func TestCanInspectSchedulerOverHTTP(t *testing.T) {
+	scheduler, _ := newTestScheduler(t)
+	require.NoError(t, scheduler.Add("purge", "0 3 * * MON-FRI", func(ctx context.Context, tick int64, at time.Time) error { return nil }))
+
+	recorder := httptest.NewRecorder()
+	scheduler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/jobs", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	var statuses []map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "purge", statuses[0]["name"])
+	assert.Equal(t, "0 3 * * MON-FRI", statuses[0]["schedule"])
+	assert.Equal(t, "2026-03-03T03:00:00Z", statuses[0]["nextRun"])
+	assert.NotContains(t, statuses[0], "lastRun")
+}
+
+func waitForScheduledRun(t *testing.T, runs <-chan time.Time) time.Time {
+	t.Helper()
+	select {
+	case at := <-runs:
+		return at.UTC()
+	case <-time.After(time.Second):
+		require.FailNow(t, "the scheduled job did not run")
+		return time.Time{}
+	}
+}
@@ -16,17 +16,32 @@ func (t Time) AsTime() time.Time {
 
 // Now returns the current local time
 func Now() Time {
-	return (Time)(time.Now())
+	return NowWithClock(RealClock{})
+}
+
+// NowWithClock returns the current local time of the given Clock
+func NowWithClock(clock Clock) Time {
+	return (Time)(clock.Now())
 }
 
 // NowIn returns the current time in the given location
 func NowIn(loc *time.Location) Time {
-	return (Time)(time.Now().In(loc))
+	return NowInWithClock(RealClock{}, loc)
+}
+
+// NowInWithClock returns the current time of the given Clock in the given location
+func NowInWithClock(clock Clock, loc *time.Location) Time {
+	return (Time)(clock.Now().In(loc))
 }
 
 // NowUTC returns the current UTC time
 func NowUTC() Time {
-	return (Time)(time.Now().UTC())
+	return NowUTCWithClock(RealClock{})
+}
+
+// NowUTCWithClock returns the current UTC time of the given Clock
+func NowUTCWithClock(clock Clock) Time {
+	return (Time)(clock.Now().UTC())
 }
 
 // Date returns a new Date
@@ -109,9 +124,23 @@ func ParseTime(value string) (Time, error) {
 	return ParseTimeIn(value, time.Now().Location())
 }
 
+// ParseTimeWithClock parses the given string for a Time, if the Time is not UTC it is set in the location of the given Clock
+//
+// "now", "today", "tomorrow", and "yesterday" are relative to the given Clock.
+func ParseTimeWithClock(clock Clock, value string) (Time, error) {
+	return ParseTimeInWithClock(clock, value, clock.Now().Location())
+}
+
 // ParseTimeIn parses the given string for a Time, if the Time is not UTC it is set in the given location
 func ParseTimeIn(value string, loc *time.Location) (Time, error) {
-	now := NowIn(loc)
+	return ParseTimeInWithClock(RealClock{}, value, loc)
+}
+
+// ParseTimeInWithClock parses the given string for a Time, if the Time is not UTC it is set in the given location
+//
+// "now", "today", "tomorrow", and "yesterday" are relative to the given Clock.
+func ParseTimeInWithClock(clock Clock, value string, loc *time.Location) (Time, error) {
+	now := NowInWithClock(clock, loc)
 	switch strings.ToLower(strings.TrimSpace(value)) {
 	case "now":
 		return now, nil
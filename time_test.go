@@ -134,3 +134,39 @@ func TestCanParseTime(t *testing.T) {
 	_, err = core.ParseTimeIn("2006-01-02", loc)
 	require.NoError(t, err)
 }
+
+func TestCanUseTimeWithClock(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	clock := core.NewFakeClock(time.Date(2026, time.March, 31, 23, 59, 59, 0, tokyo))
+
+	assert.Equal(t, "2026-03-31 23:59:59 +0900 JST", core.NowWithClock(clock).String())
+	assert.Equal(t, "2026-03-31 14:59:59 +0000 UTC", core.NowUTCWithClock(clock).String())
+	assert.Equal(t, "2026-03-31 16:59:59 +0200 CEST", core.NowInWithClock(clock, mustLoadLocation(t, "Europe/Paris")).String())
+
+	today, err := core.ParseTimeWithClock(clock, "today")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-31 00:00:00 +0900 JST", today.String())
+
+	clock.Advance(time.Second) // midnight in Tokyo
+	tests := map[string]string{
+		"now":       "2026-04-01 00:00:00 +0900 JST",
+		"today":     "2026-04-01 00:00:00 +0900 JST",
+		"tomorrow":  "2026-04-02 00:00:00 +0900 JST",
+		"yesterday": "2026-03-31 00:00:00 +0900 JST",
+	}
+	for value, expected := range tests {
+		parsed, err := core.ParseTimeInWithClock(clock, value, tokyo)
+		require.NoError(t, err)
+		assert.Equal(t, expected, parsed.String(), "Failed to parse %s", value)
+	}
+	parsed, err := core.ParseTimeInWithClock(clock, "today", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-31 00:00:00 +0000 UTC", parsed.String())
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return location
+}
@@ -11,7 +11,12 @@ type Timestamp time.Time
 
 // TimestampNow returns a Timestamp at the time of its call
 func TimestampNow() Timestamp {
-	return Timestamp(time.Now())
+	return TimestampNowWithClock(RealClock{})
+}
+
+// TimestampNowWithClock returns a Timestamp at the current time of the given Clock
+func TimestampNowWithClock(clock Clock) Timestamp {
+	return Timestamp(clock.Now())
 }
 
 // TimestampFromJSEpoch returns a Timestamp from a JS Epoch
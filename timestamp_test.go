@@ -64,3 +64,10 @@ func TestShouldFailUnmarshalTimestampWithInvalidPayload(t *testing.T) {
 	require.Error(t, err, "should fail to unmarshal")
 	assert.Equal(t, `strconv.ParseInt: parsing "hello": invalid syntax`, err.Error())
 }
+
+func TestCanCreateTimestampWithClock(t *testing.T) {
+	clock := core.NewFakeClock(time.Unix(0, 1534318964318*int64(time.Millisecond)))
+	assert.Equal(t, int64(1534318964318), core.TimestampNowWithClock(clock).JSEpoch())
+	clock.Advance(time.Second)
+	assert.Equal(t, int64(1534318965318), core.TimestampNowWithClock(clock).JSEpoch())
+}
@@ -1,52 +1,133 @@
 package core
 
 import (
+	"crypto/rand"
 	"encoding/binary"
+	"io"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type UUID uuid.UUID
 
-// EncodeUUID encodes a UUID in a 22 char long string
-func EncodeUUID(uuid uuid.UUID) string {
-	bytes, _ := uuid.MarshalBinary()
-	msi64 := binary.BigEndian.Uint64(bytes[:8])
-	lsi64 := binary.BigEndian.Uint64(bytes[8:])
+var (
+	// UUIDNamespaceDNS is the namespace for UUIDs generated from fully qualified domain names (RFC 9562)
+	UUIDNamespaceDNS = UUID(uuid.NameSpaceDNS)
+	// UUIDNamespaceURL is the namespace for UUIDs generated from URLs (RFC 9562)
+	UUIDNamespaceURL = UUID(uuid.NameSpaceURL)
+	// UUIDNamespaceOID is the namespace for UUIDs generated from ISO OIDs (RFC 9562)
+	UUIDNamespaceOID = UUID(uuid.NameSpaceOID)
+	// UUIDNamespaceX500 is the namespace for UUIDs generated from X.500 DNs (RFC 9562)
+	UUIDNamespaceX500 = UUID(uuid.NameSpaceX500)
+)
 
-	result := strings.Builder{}
-	result.WriteString(encodeUInt64(msi64))
-	result.WriteString(encodeUInt64(lsi64))
-	return result.String()
+// lastV7 keeps the timestamp of the last version 7 UUID so they are strictly increasing within a millisecond
+var lastV7 struct {
+	timestamp int64 // in 1/4096th of millisecond
+	mutex     sync.Mutex
 }
 
-// DecodeUUID decodes a UUID from a 22 char long string
-func DecodeUUID(encoded string) (uuid.UUID, error) {
-	bytes := make([]byte, 16)
-	binary.BigEndian.PutUint64(bytes, decodeUInt64(encoded[:11]))
-	binary.BigEndian.PutUint64(bytes[8:], decodeUInt64(encoded[11:]))
-	return uuid.FromBytes(bytes)
-}
-
-func encodeUInt64(number uint64) string {
-	base := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
-	result := ""
-	for i := 0; i < 11; i++ {
-		result = string(base[number&0x3f]) + result
-		number = number >> 6
+// NewUUID returns a new random (version 4) UUID
+//
+// NewUUID panics if the random source fails
+func NewUUID() UUID {
+	return Must(NewUUIDFromReader(rand.Reader))
+}
+
+// NewUUIDFromReader returns a new random (version 4) UUID that reads its random bytes from the given reader
+func NewUUIDFromReader(reader io.Reader) (UUID, error) {
+	id, err := uuid.NewRandomFromReader(reader)
+	return UUID(id), err
+}
+
+// NewUUIDv7 returns a new time-ordered (version 7) UUID
+//
+// UUIDs generated by the same process are strictly increasing, which makes them good database keys,
+// unless the clock goes back by more than a millisecond.
+//
+// NewUUIDv7 panics if the random source fails
+func NewUUIDv7() UUID {
+	return Must(NewUUIDv7FromReader(rand.Reader))
+}
+
+// NewUUIDv7FromReader returns a new time-ordered (version 7) UUID that reads its random bytes from the given reader
+func NewUUIDv7FromReader(reader io.Reader) (UUID, error) {
+	return NewUUIDv7WithClock(RealClock{}, reader)
+}
+
+// NewUUIDv7WithClock returns a new time-ordered (version 7) UUID stamped with the current time of the given Clock
+// that reads its random bytes from the given reader
+func NewUUIDv7WithClock(clock Clock, reader io.Reader) (UUID, error) {
+	return newUUIDv7(clock.Now(), reader)
+}
+
+// newUUIDv7 builds a version 7 UUID with the sub-millisecond precision of RFC 9562, section 6.2, method 3
+func newUUIDv7(now time.Time, reader io.Reader) (UUID, error) {
+	var id UUID
+	if _, err := io.ReadFull(reader, id[6:]); err != nil {
+		return UUID(uuid.Nil), err
+	}
+
+	timestamp := now.UnixMilli()<<12 | (now.UnixNano()%int64(time.Millisecond))*4096/int64(time.Millisecond)
+	lastV7.mutex.Lock()
+	// only UUIDs of the same millisecond are bumped, so a clock that goes back, like a FakeClock, is honored
+	if timestamp <= lastV7.timestamp && lastV7.timestamp-timestamp < 1<<12 {
+		timestamp = lastV7.timestamp + 1
 	}
-	return result
+	lastV7.timestamp = timestamp
+	lastV7.mutex.Unlock()
+
+	milliseconds := uint64(timestamp >> 12)
+	id[0] = byte(milliseconds >> 40)
+	id[1] = byte(milliseconds >> 32)
+	binary.BigEndian.PutUint32(id[2:6], uint32(milliseconds))
+	binary.BigEndian.PutUint16(id[6:8], 0x7000|uint16(timestamp&0x0fff))
+	id[8] = 0x80 | (id[8] & 0x3f)
+	return id, nil
 }
 
-func decodeUInt64(compressed string) uint64 {
-	base := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
-	result := uint64(0)
-	for _, c := range compressed[:] {
-		index := strings.IndexRune(base, c)
-		result = (result << 6) | uint64(index)
+// NameUUID returns a deterministic (version 5) UUID from a namespace and a name
+//
+// The same namespace and name always give the same UUID.
+//
+// Example:
+//
+//	id := core.NameUUID(core.UUIDNamespaceURL, "https://www.acme.com/users/john")
+func NameUUID(namespace UUID, name string) UUID {
+	return UUID(uuid.NewSHA1(uuid.UUID(namespace), []byte(name)))
+}
+
+// ParseUUID parses a UUID
+//
+// The UUID can be in the standard form (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx), without hyphens,
+// in braces ({xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx}), as a URN (urn:uuid:xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx),
+// or in the 22 char long compact form given by EncodeUUID.
+func ParseUUID(value string) (UUID, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == Base64URLEncoding.EncodedLen() {
+		id, err := DecodeUUID(value)
+		return UUID(id), err
 	}
-	return result
+	id, err := uuid.Parse(value)
+	return UUID(id), err
+}
+
+// EncodeUUID encodes a UUID in a 22 char long string
+//
+// The UUID is encoded with Base64URLEncoding
+func EncodeUUID(uuid uuid.UUID) string {
+	return Base64URLEncoding.EncodeUUID(uuid)
+}
+
+// DecodeUUID decodes a UUID from a 22 char long string
+//
+// The UUID is decoded with Base64URLEncoding,
+// an EncodedUUIDLengthError, EncodedUUIDCharacterError or EncodedUUIDOverflowError is returned if the string is not valid.
+func DecodeUUID(encoded string) (uuid.UUID, error) {
+	return Base64URLEncoding.DecodeUUID(encoded)
 }
 
 // IsZero returns true if the UUID is uuid.Nil
@@ -54,6 +135,23 @@ func (id UUID) IsZero() bool {
 	return uuid.UUID(id) == uuid.Nil
 }
 
+// Version returns the version of the UUID
+func (id UUID) Version() int {
+	return int(uuid.UUID(id).Version())
+}
+
+// Time returns the time at which a time-based (version 1 or 7) UUID was generated
+//
+// For other versions, the zero Time is returned.
+func (id UUID) Time() Time {
+	switch id.Version() {
+	case 1, 7:
+		seconds, nanoseconds := uuid.UUID(id).Time().UnixTime()
+		return Time(time.Unix(seconds, nanoseconds))
+	}
+	return Time{}
+}
+
 // GetID returns the UUID as a uuid.UUID type
 //
 // implements Identifiable
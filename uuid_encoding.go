@@ -0,0 +1,194 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Encoding is a compact encoding of UUIDs
+//
+// All encodings produce fixed length strings, padded with the first character of their alphabet.
+type Encoding struct {
+	name            string
+	alphabet        string
+	length          int
+	caseInsensitive bool
+	decodeMap       [256]byte
+}
+
+// EncodedUUIDLengthError is returned when an encoded UUID does not have the length of its Encoding
+type EncodedUUIDLengthError struct {
+	Encoding string
+	Length   int
+	Expected int
+}
+
+// EncodedUUIDCharacterError is returned when an encoded UUID contains a character that is not in the alphabet of its Encoding
+type EncodedUUIDCharacterError struct {
+	Encoding  string
+	Character rune
+	Position  int
+}
+
+// EncodedUUIDOverflowError is returned when an encoded UUID does not fit in 128 bits
+type EncodedUUIDOverflowError struct {
+	Encoding string
+	Value    string
+}
+
+const invalidEncodingIndex = 0xFF
+
+var (
+	// Base64URLEncoding encodes UUIDs in 22 characters with the URL-safe base64 alphabet (RFC 4648)
+	//
+	// This is the encoding used by EncodeUUID and DecodeUUID.
+	Base64URLEncoding = newEncoding("base64url", "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", 22, false)
+
+	// Base58Encoding encodes UUIDs in 22 characters with the Bitcoin base58 alphabet
+	//
+	// The alphabet has no 0, O, I or l so the encoded UUIDs can be read by humans.
+	Base58Encoding = newEncoding("base58", "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", 22, false)
+
+	// Base62Encoding encodes UUIDs in 22 alphanumeric characters
+	Base62Encoding = newEncoding("base62", "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 22, false)
+
+	// Crockford32Encoding encodes UUIDs in 26 characters with Crockford's base32 alphabet
+	//
+	// The decoding is case insensitive and accepts O for 0, I and L for 1, so the encoded UUIDs can be typed by humans
+	// and used in case insensitive file systems.
+	Crockford32Encoding = newEncoding("crockford32", "0123456789ABCDEFGHJKMNPQRSTVWXYZ", 26, true).withAliases("O0", "I1", "L1")
+)
+
+func newEncoding(name, alphabet string, length int, caseInsensitive bool) *Encoding {
+	encoding := &Encoding{name: name, alphabet: alphabet, length: length, caseInsensitive: caseInsensitive}
+	for i := range encoding.decodeMap {
+		encoding.decodeMap[i] = invalidEncodingIndex
+	}
+	for i := 0; i < len(alphabet); i++ {
+		encoding.decodeMap[alphabet[i]] = byte(i)
+		if caseInsensitive {
+			encoding.decodeMap[strings.ToLower(alphabet[i : i+1])[0]] = byte(i)
+		}
+	}
+	return encoding
+}
+
+// withAliases adds characters that decode like another one, each alias is a pair "<alias><character>"
+func (encoding *Encoding) withAliases(aliases ...string) *Encoding {
+	for _, alias := range aliases {
+		index := encoding.decodeMap[alias[1]]
+		encoding.decodeMap[alias[0]] = index
+		if encoding.caseInsensitive {
+			encoding.decodeMap[strings.ToLower(alias[:1])[0]] = index
+		}
+	}
+	return encoding
+}
+
+// String returns the name of the Encoding
+//
+// implements fmt.Stringer
+func (encoding *Encoding) String() string {
+	return encoding.name
+}
+
+// EncodedLen returns the length of the UUIDs encoded with this Encoding
+func (encoding *Encoding) EncodedLen() int {
+	return encoding.length
+}
+
+// EncodeUUID encodes a UUID with this Encoding
+func (encoding *Encoding) EncodeUUID(id uuid.UUID) string {
+	if encoding == Base64URLEncoding {
+		// For compatibility, each half of the UUID is encoded on its own in 11 characters
+		return encoding.encodeUInt64(binary.BigEndian.Uint64(id[:8])) + encoding.encodeUInt64(binary.BigEndian.Uint64(id[8:]))
+	}
+	base := big.NewInt(int64(len(encoding.alphabet)))
+	number := new(big.Int).SetBytes(id[:])
+	digit := new(big.Int)
+	result := make([]byte, encoding.length)
+	for i := encoding.length - 1; i >= 0; i-- {
+		number.DivMod(number, base, digit)
+		result[i] = encoding.alphabet[digit.Int64()]
+	}
+	return string(result)
+}
+
+// DecodeUUID decodes a UUID encoded with this Encoding
+//
+// an EncodedUUIDLengthError, EncodedUUIDCharacterError or EncodedUUIDOverflowError is returned if the string is not valid.
+func (encoding *Encoding) DecodeUUID(encoded string) (uuid.UUID, error) {
+	if len(encoded) != encoding.length {
+		return uuid.Nil, EncodedUUIDLengthError{Encoding: encoding.name, Length: len(encoded), Expected: encoding.length}
+	}
+	indexes := make([]byte, len(encoded))
+	for position, character := range encoded {
+		if character > 0xFF || encoding.decodeMap[character] == invalidEncodingIndex {
+			return uuid.Nil, EncodedUUIDCharacterError{Encoding: encoding.name, Character: character, Position: position}
+		}
+		indexes[position] = encoding.decodeMap[character]
+	}
+
+	var id uuid.UUID
+	if encoding == Base64URLEncoding {
+		// each half carries 66 bits, the 2 highest bits must be 0
+		if indexes[0] >= 16 || indexes[11] >= 16 {
+			return uuid.Nil, EncodedUUIDOverflowError{Encoding: encoding.name, Value: encoded}
+		}
+		binary.BigEndian.PutUint64(id[:8], decodeUInt64(indexes[:11]))
+		binary.BigEndian.PutUint64(id[8:], decodeUInt64(indexes[11:]))
+		return id, nil
+	}
+	base := big.NewInt(int64(len(encoding.alphabet)))
+	number := new(big.Int)
+	for _, index := range indexes {
+		number.Mul(number, base).Add(number, big.NewInt(int64(index)))
+	}
+	if number.BitLen() > 128 {
+		return uuid.Nil, EncodedUUIDOverflowError{Encoding: encoding.name, Value: encoded}
+	}
+	number.FillBytes(id[:])
+	return id, nil
+}
+
+func (encoding *Encoding) encodeUInt64(number uint64) string {
+	result := make([]byte, 11)
+	for i := 10; i >= 0; i-- {
+		result[i] = encoding.alphabet[number&0x3f]
+		number = number >> 6
+	}
+	return string(result)
+}
+
+func decodeUInt64(indexes []byte) uint64 {
+	result := uint64(0)
+	for _, index := range indexes {
+		result = (result << 6) | uint64(index)
+	}
+	return result
+}
+
+// Error returns the string representation of this error
+//
+// implements error
+func (err EncodedUUIDLengthError) Error() string {
+	return fmt.Sprintf("invalid %s UUID length %d, expected %d", err.Encoding, err.Length, err.Expected)
+}
+
+// Error returns the string representation of this error
+//
+// implements error
+func (err EncodedUUIDCharacterError) Error() string {
+	return fmt.Sprintf("invalid %s UUID character %q at position %d", err.Encoding, err.Character, err.Position)
+}
+
+// Error returns the string representation of this error
+//
+// implements error
+func (err EncodedUUIDOverflowError) Error() string {
+	return fmt.Sprintf("invalid %s UUID %q, the value does not fit in 128 bits", err.Encoding, err.Value)
+}
@@ -1,9 +1,12 @@
 package core_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gildas/go-core"
 	"github.com/google/uuid"
@@ -173,3 +176,171 @@ func TestCanXMLEntityUnmarshalUUID(t *testing.T) {
 	require.NoError(t, err, "Cannot unmarshal UUID")
 	assert.Equal(t, uuid.Nil, uuid.UUID(decoded.ID))
 }
+
+func TestShouldFailDecodingInvalidUUID(t *testing.T) {
+	_, err := core.DecodeUUID("uTTgLJbLTeO6fDt9r1kxMQ"[:10])
+	require.Error(t, err, "Should have failed to decode a short UUID")
+	var lengthError core.EncodedUUIDLengthError
+	require.ErrorAs(t, err, &lengthError)
+	assert.Equal(t, 10, lengthError.Length)
+	assert.Equal(t, 22, lengthError.Expected)
+
+	_, err = core.DecodeUUID("uTTgLJbLTe!6fDt9r1kxMQ")
+	require.Error(t, err, "Should have failed to decode a UUID with an invalid character")
+	var characterError core.EncodedUUIDCharacterError
+	require.ErrorAs(t, err, &characterError)
+	assert.Equal(t, '!', characterError.Character)
+	assert.Equal(t, 10, characterError.Position)
+
+	_, err = core.DecodeUUID("____________________w")
+	require.Error(t, err, "Should have failed to decode a UUID with an invalid length")
+
+	_, err = core.DecodeUUID("______________________")
+	require.Error(t, err, "Should have failed to decode a UUID that overflows")
+	assert.ErrorAs(t, err, &core.EncodedUUIDOverflowError{})
+
+	_, err = core.DecodeUUID("")
+	require.Error(t, err, "Should have failed to decode an empty UUID")
+}
+
+func TestCanEncodeUUIDWithEncodings(t *testing.T) {
+	ids := []uuid.UUID{
+		uuid.MustParse("b934e02c-96cb-4de3-ba7c-3b7daf593131"),
+		uuid.Nil,
+		uuid.Max,
+	}
+	encodings := []*core.Encoding{core.Base64URLEncoding, core.Base58Encoding, core.Base62Encoding, core.Crockford32Encoding}
+	for _, encoding := range encodings {
+		t.Run(encoding.String(), func(t *testing.T) {
+			for _, id := range ids {
+				encoded := encoding.EncodeUUID(id)
+				assert.Len(t, encoded, encoding.EncodedLen())
+				decoded, err := encoding.DecodeUUID(encoded)
+				require.NoErrorf(t, err, "Failed to decode %s", encoded)
+				assert.Equal(t, id, decoded)
+			}
+		})
+	}
+	id := uuid.MustParse("b934e02c-96cb-4de3-ba7c-3b7daf593131")
+	assert.Equal(t, core.EncodeUUID(id), core.Base64URLEncoding.EncodeUUID(id))
+	assert.Equal(t, "0000000000000000000000", core.Base62Encoding.EncodeUUID(uuid.Nil))
+	assert.Equal(t, "1111111111111111111111", core.Base58Encoding.EncodeUUID(uuid.Nil))
+}
+
+func TestCanDecodeCrockford32UUIDTypedByHumans(t *testing.T) {
+	id := uuid.MustParse("b934e02c-96cb-4de3-ba7c-3b7daf593131")
+	encoded := core.Crockford32Encoding.EncodeUUID(id)
+	assert.Equal(t, strings.ToUpper(encoded), encoded)
+
+	decoded, err := core.Crockford32Encoding.DecodeUUID(strings.ToLower(encoded))
+	require.NoError(t, err, "Failed to decode lower case UUID")
+	assert.Equal(t, id, decoded)
+
+	decoded, err = core.Crockford32Encoding.DecodeUUID(strings.ReplaceAll(strings.ReplaceAll(encoded, "0", "O"), "1", "l"))
+	require.NoError(t, err, "Failed to decode UUID with aliases")
+	assert.Equal(t, id, decoded)
+
+	_, err = core.Crockford32Encoding.DecodeUUID("U" + encoded[1:])
+	assert.ErrorAs(t, err, &core.EncodedUUIDCharacterError{})
+
+	_, err = core.Crockford32Encoding.DecodeUUID("Z" + encoded[1:])
+	assert.ErrorAs(t, err, &core.EncodedUUIDOverflowError{})
+
+	_, err = core.Base58Encoding.DecodeUUID("0000000000000000000000")
+	assert.ErrorAs(t, err, &core.EncodedUUIDCharacterError{})
+}
+
+func TestCanCreateUUID(t *testing.T) {
+	id := core.NewUUID()
+	assert.False(t, id.IsZero())
+	assert.Equal(t, 4, id.Version())
+	assert.NotEqual(t, id, core.NewUUID())
+
+	id, err := core.NewUUIDFromReader(bytes.NewReader(make([]byte, 16)))
+	require.NoError(t, err, "Failed to create UUID")
+	assert.Equal(t, "00000000-0000-4000-8000-000000000000", id.String())
+
+	_, err = core.NewUUIDFromReader(bytes.NewReader(make([]byte, 4)))
+	assert.Error(t, err, "Should have failed with a short random source")
+	assert.True(t, id.Time().IsZero(), "Version 4 UUIDs do not carry a time")
+}
+
+func TestCanCreateUUIDv7(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	id, err := core.NewUUIDv7FromReader(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 10)))
+	require.NoError(t, err, "Failed to create UUID")
+	after := time.Now()
+	assert.Equal(t, 7, id.Version())
+	assert.Equal(t, uuid.RFC4122, uuid.UUID(id).Variant())
+	assert.False(t, id.Time().AsTime().Before(before), "UUID time %s should not be before %s", id.Time(), before)
+	assert.False(t, id.Time().AsTime().After(after), "UUID time %s should not be after %s", id.Time(), after)
+
+	previous := core.NewUUIDv7()
+	for i := 0; i < 1000; i++ {
+		next := core.NewUUIDv7()
+		require.Less(t, previous.String(), next.String(), "UUIDs should be strictly increasing")
+		previous = next
+	}
+
+	_, err = core.NewUUIDv7FromReader(bytes.NewReader(make([]byte, 4)))
+	assert.Error(t, err, "Should have failed with a short random source")
+}
+
+func TestCanCreateUUIDv7WithClock(t *testing.T) {
+	_ = core.NewUUIDv7() // a UUID of the real time must not push the fake time forward
+	clock := core.NewFakeClock(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	id, err := core.NewUUIDv7WithClock(clock, bytes.NewReader(bytes.Repeat([]byte{0xFF}, 10)))
+	require.NoError(t, err, "Failed to create UUID")
+	assert.Equal(t, 7, id.Version())
+	assert.Equal(t, clock.Now(), id.Time().AsTime().UTC())
+
+	next, err := core.NewUUIDv7WithClock(clock, bytes.NewReader(bytes.Repeat([]byte{0x00}, 10)))
+	require.NoError(t, err, "Failed to create UUID")
+	assert.Less(t, id.String(), next.String(), "UUIDs of the same millisecond should be strictly increasing")
+	assert.Equal(t, clock.Now(), next.Time().AsTime().UTC().Truncate(time.Millisecond))
+
+	clock.Advance(time.Hour)
+	later, err := core.NewUUIDv7WithClock(clock, bytes.NewReader(bytes.Repeat([]byte{0x00}, 10)))
+	require.NoError(t, err, "Failed to create UUID")
+	assert.Equal(t, clock.Now(), later.Time().AsTime().UTC())
+}
+
+func TestCanCreateNameUUID(t *testing.T) {
+	id := core.NameUUID(core.UUIDNamespaceDNS, "www.example.com")
+	assert.Equal(t, "2ed6657d-e927-568b-95e1-2665a8aea6a2", id.String())
+	assert.Equal(t, 5, id.Version())
+	assert.Equal(t, id, core.NameUUID(core.UUIDNamespaceDNS, "www.example.com"))
+	assert.NotEqual(t, id, core.NameUUID(core.UUIDNamespaceURL, "www.example.com"))
+}
+
+func TestCanParseUUID(t *testing.T) {
+	expected := core.UUID(uuid.MustParse("b934e02c-96cb-4de3-ba7c-3b7daf593131"))
+	values := []string{
+		"b934e02c-96cb-4de3-ba7c-3b7daf593131",
+		"B934E02C-96CB-4DE3-BA7C-3B7DAF593131",
+		"b934e02c96cb4de3ba7c3b7daf593131",
+		"{b934e02c-96cb-4de3-ba7c-3b7daf593131}",
+		"urn:uuid:b934e02c-96cb-4de3-ba7c-3b7daf593131",
+		core.EncodeUUID(uuid.UUID(expected)),
+		" b934e02c-96cb-4de3-ba7c-3b7daf593131 ",
+	}
+	for _, value := range values {
+		id, err := core.ParseUUID(value)
+		require.NoErrorf(t, err, "Failed to parse %s", value)
+		assert.Equal(t, expected, id)
+	}
+
+	_, err := core.ParseUUID("b934e02c-96cb-4de3-ba7c")
+	assert.Error(t, err, "Should have failed to parse a short UUID")
+	_, err = core.ParseUUID("uTTgLJbLTe!6fDt9r1kxMQ")
+	assert.ErrorAs(t, err, &core.EncodedUUIDCharacterError{})
+}
+
+func TestCanGetUUIDTime(t *testing.T) {
+	id := core.UUID(uuid.Must(uuid.NewUUID()))
+	assert.Equal(t, 1, id.Version())
+	assert.WithinDuration(t, time.Now(), id.Time().AsTime(), time.Second)
+
+	id = core.UUID(uuid.MustParse("017f22e2-79b0-7cc3-98c4-dc0c0c07398f")) // RFC 9562, Appendix A.6
+	assert.Equal(t, "2022-02-22T19:22:22Z", id.Time().AsTime().UTC().Format(time.RFC3339))
+}